@@ -4,15 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
-	openExchange "main/internal/api/openexchange"
+	"main/internal/alerts"
+	"main/internal/api"
+	"main/internal/api/cache"
+	"main/internal/api/coalesce"
+	"main/internal/api/poller"
+	"main/internal/chaos"
 	"main/internal/configuration"
 	"main/internal/errs"
 	"main/internal/errs/currency"
 	logging "main/internal/errs/log"
+	alertsHandler "main/internal/handlers/alerts"
+	"main/internal/handlers/auth"
+	chaosAdmin "main/internal/handlers/chaos"
+	"main/internal/handlers/currencies"
 	"main/internal/handlers/exchange"
+	"main/internal/handlers/health"
+	"main/internal/handlers/history"
+	"main/internal/handlers/live"
 	"main/internal/handlers/rates"
+	"main/internal/handlers/stream"
+	"main/internal/handlers/timeseries"
+	"main/internal/handlers/tokens"
+	"main/internal/handlers/usage"
+	"main/internal/repository"
 	"main/internal/repository/memory"
+	"main/internal/repository/sqlite"
 	"net/http"
 	"os"
 	"os/signal"
@@ -32,7 +51,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	router, err := setupRouter(cfg)
+	app, err := setupRouter(cfg)
 	if err != nil {
 		slog.Error("Failed to setup router", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -40,16 +59,40 @@ func main() {
 
 	srv := &http.Server{
 		Addr:              cfg.ListenAddress,
-		Handler:           router,
+		Handler:           app.router,
 		ReadHeaderTimeout: cfg.ReadTimeout * time.Second,
 		ReadTimeout:       cfg.ReadTimeout * time.Second,
 		WriteTimeout:      cfg.WriteTimeout * time.Second,
 	}
 
-	runServer(srv, cfg)
+	for _, hook := range app.onShutdown {
+		srv.RegisterOnShutdown(hook)
+	}
+
+	runServer(srv, cfg, app.workers)
+
+	for _, closer := range app.closers {
+		if err := closer.Close(); err != nil {
+			slog.Error("Failed to close", slog.String("err", err.Error()))
+		}
+	}
+}
+
+type application struct {
+	router  *gin.Engine
+	workers []worker
+	// onShutdown ends long-lived requests, which http.Server.Shutdown would wait for.
+	onShutdown []func()
+	// closers release resources once the server and the workers stopped.
+	closers []io.Closer
 }
 
-func runServer(srv *http.Server, cfg configuration.Configuration) {
+func runServer(srv *http.Server, cfg configuration.Configuration, workers []worker) {
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	runningWorkers := startWorkers(workersCtx, workers)
+
 	go func() {
 		slog.Info("Starting server...", slog.String("address", cfg.ListenAddress))
 
@@ -73,13 +116,21 @@ func runServer(srv *http.Server, cfg configuration.Configuration) {
 		slog.Error("Server forced to shutdown", slog.String("err", err.Error()))
 	}
 
+	stopWorkers()
+
+	if err := waitWorkers(ctx, runningWorkers); err != nil {
+		slog.Error("Background workers did not stop in time", slog.String("err", err.Error()))
+	}
+
 	slog.Info("Server stopped")
 }
 
-func setupRouter(cfg configuration.Configuration) (*gin.Engine, error) {
+func setupRouter(cfg configuration.Configuration) (application, error) {
 	router := gin.Default()
 
-	api := router.Group("/")
+	routes := router.Group("/")
+
+	adminToken := os.Getenv(adminTokenEnv(cfg.Admin))
 
 	var errorHandler errs.ErrorHandler
 
@@ -88,20 +139,253 @@ func setupRouter(cfg configuration.Configuration) (*gin.Engine, error) {
 		errorHandler = logging.NewErrorHandler(errorHandler)
 	}
 
-	openExchangeAPI, err := openExchange.New(cfg.APIURL, os.Getenv("APP_ID"))
+	upstream, err := newUpstream(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("error while preparing exchange API: %w", err)
+		return application{}, fmt.Errorf("error while preparing exchange API: %w", err)
+	}
+
+	var (
+		currencyRateAPI api.CurrencyRate
+		ratePoller      *poller.Poller
+	)
+
+	workers := upstream.workers
+
+	currencyRateAPI = coalesce.New(upstream.chain, cfg.FetchTimeout*time.Second)
+
+	if cfg.PollInterval > 0 || cfg.CacheTTL > 0 {
+		for _, providerCfg := range cfg.Providers {
+			if providerCfg.Symbols {
+				slog.Warn("Symbols has no effect with the cache or the poller, they fetch the whole table",
+					slog.String("provider", providerCfg.Name))
+			}
+		}
+	}
+
+	caches := make(map[string]health.Cache)
+
+	switch {
+	case cfg.PollInterval > 0:
+		ratePoller = poller.New(currencyRateAPI, cfg.PollInterval*time.Second)
+		workers = append(workers, ratePoller)
+		currencyRateAPI = ratePoller
+	case cfg.CacheTTL > 0:
+		rateCache := cache.New(currencyRateAPI, cfg.CacheTTL*time.Second)
+		caches["rates"] = rateCache
+		currencyRateAPI = rateCache
+	}
+
+	historicalAPI := cache.NewHistory(upstream.chain)
+	caches["history"] = historicalAPI
+
+	// Faults are injected per request, above the cache and the poller: the damage hits the requested
+	// currencies and is gone as soon as chaos is switched off.
+	ratesAPI := currencyRateAPI
+	if upstream.injector != nil {
+		ratesAPI = chaos.NewProvider(currencyRateAPI, upstream.injector)
+	}
+
+	ratesHandler := rates.NewHandler(ratesAPI, historicalAPI, errorHandler)
+	routes.GET("/rates", ratesHandler.Handle)
+
+	var onShutdown []func()
+
+	// Without the background poller, a dedicated one watches the cached table for the streams
+	// and the websocket subscriptions.
+	rateUpdates := ratePoller
+	if rateUpdates == nil && cfg.Stream.CheckInterval > 0 {
+		rateUpdates = poller.New(currencyRateAPI, cfg.Stream.CheckInterval*time.Second)
+		workers = append(workers, rateUpdates)
+	}
+
+	if rateUpdates != nil {
+		streamHandler := stream.NewHandler(rateUpdates, errorHandler, cfg.Stream.HeartbeatInterval*time.Second)
+		routes.GET("/rates/stream", streamHandler.Handle)
+
+		onShutdown = append(onShutdown, streamHandler.Shutdown)
+	}
+
+	historyHandler := history.NewHandler(historicalAPI, errorHandler)
+	routes.GET("/rates/history", historyHandler.Handle)
+
+	timeSeriesHandler := timeseries.NewHandler(
+		historicalAPI,
+		errorHandler,
+		cfg.TimeSeries.MaxDays,
+		cfg.TimeSeries.Concurrency,
+	)
+	routes.GET("/rates/timeseries", timeSeriesHandler.Handle)
+
+	currencyRateRepo, repoCloser, err := newCurrencyRateRepo(cfg.Repository)
+	if err != nil {
+		return application{}, fmt.Errorf("error while preparing token repository: %w", err)
+	}
+
+	var closers []io.Closer
+	if repoCloser != nil {
+		closers = append(closers, repoCloser)
+	}
+
+	var exchangeRepo repository.CurrencyRate = currencyRateRepo
+	if upstream.injector != nil {
+		exchangeRepo = chaos.NewRepository(currencyRateRepo, upstream.injector)
+	}
+
+	exchangeHandler := exchange.NewHandler(exchangeRepo, errorHandler)
+
+	routes.GET("/exchange", exchangeHandler.Handle)
+
+	if rateUpdates != nil {
+		liveHandler := live.NewHandler(rateUpdates, exchangeRepo, live.Limits{
+			MaxSubscriptions: cfg.WebSocket.MaxSubscriptions,
+			MaxPending:       cfg.WebSocket.MaxPending,
+			WriteTimeout:     cfg.WebSocket.WriteTimeout * time.Second,
+			PingInterval:     cfg.WebSocket.PingInterval * time.Second,
+			AllowedOrigins:   cfg.WebSocket.AllowedOrigins,
+		})
+		routes.GET("/live", liveHandler.Handle)
+
+		onShutdown = append(onShutdown, liveHandler.Shutdown)
 	}
 
-	ratesHandler := rates.NewHandler(openExchangeAPI, errorHandler)
-	api.GET("/rates", ratesHandler.Handle)
+	if rateUpdates != nil && cfg.Alerts.StorePath != "" {
+		// Anyone registering an alert decides where the service sends requests, and sees every webhook.
+		if adminToken == "" {
+			return application{}, fmt.Errorf("alerts require the admin token in %s", adminTokenEnv(cfg.Admin))
+		}
+
+		alertEngine, err := newAlertEngine(cfg.Alerts, rateUpdates, exchangeRepo)
+		if err != nil {
+			return application{}, fmt.Errorf("error while preparing alerts: %w", err)
+		}
+
+		workers = append(workers, alertEngine)
+
+		handler := alertsHandler.NewHandler(alertEngine, errorHandler)
+		alertRoutes := router.Group("/alerts", auth.NewHandler(adminToken).Handle)
+		alertRoutes.POST("", handler.HandleCreate)
+		alertRoutes.GET("", handler.HandleList)
+		alertRoutes.GET("/dead-letters", handler.HandleDeadLetters)
+		alertRoutes.GET("/:id", handler.HandleGet)
+		alertRoutes.DELETE("/:id", handler.HandleDelete)
+	}
 
-	currencyRateRepo := memory.NewCurrencyRateRepo()
-	exchangeHandler := exchange.NewHandler(currencyRateRepo, errorHandler)
+	currenciesHandler := currencies.NewHandler(upstream.currencyNames, currencyRateRepo, errorHandler)
+	routes.GET("/currencies", currenciesHandler.Handle)
 
-	api.GET("/exchange", exchangeHandler.Handle)
+	var pollerStatus health.Poller
+	if ratePoller != nil {
+		pollerStatus = ratePoller
+	}
+
+	healthHandler := health.NewHandler(upstream.breakers, len(cfg.Providers), pollerStatus, caches)
+	routes.GET("/health", healthHandler.Handle)
+
+	admin := router.Group("/admin")
 
-	return router, nil
+	// The admin endpoints change what the service does, so they are only served with a token.
+	if adminToken != "" {
+		admin.Use(auth.NewHandler(adminToken).Handle)
+
+		tokensHandler := tokens.NewHandler(currencyRateRepo, errorHandler)
+		admin.GET("/tokens", tokensHandler.HandleList)
+		admin.GET("/tokens/:symbol", tokensHandler.HandleGet)
+		admin.POST("/tokens", tokensHandler.HandleCreate)
+		admin.PUT("/tokens/:symbol", tokensHandler.HandleUpdate)
+		admin.DELETE("/tokens/:symbol", tokensHandler.HandleDelete)
+
+		if upstream.injector != nil {
+			chaosHandler := chaosAdmin.NewHandler(upstream.injector)
+			admin.GET("/chaos", chaosHandler.Handle)
+			admin.PUT("/chaos", chaosHandler.HandleUpdate)
+		}
+
+		usageHandler := usage.NewHandler(upstream.budgets)
+		admin.GET("/usage", usageHandler.Handle)
+	} else if upstream.injector != nil {
+		slog.Warn("No admin token, the chaos settings can not be changed at runtime")
+	}
+
+	return application{
+		router:     router,
+		workers:    workers,
+		onShutdown: onShutdown,
+		closers:    closers,
+	}, nil
+}
+
+// adminTokenEnv names the environment variable holding the admin token.
+func adminTokenEnv(cfg configuration.Admin) string {
+	if cfg.TokenEnv == "" {
+		return "ADMIN_TOKEN"
+	}
+
+	return cfg.TokenEnv
+}
+
+// tokenRepository serves /exchange and /currencies and is edited with /admin/tokens.
+type tokenRepository interface {
+	repository.CurrencyRate
+	repository.CurrencyList
+	tokens.Store
+}
+
+// newCurrencyRateRepo seeds the repository with TokensPath, the default dataset when it is empty.
+// A sqlite database is only seeded when it has no tokens yet.
+func newCurrencyRateRepo(cfg configuration.Repository) (tokenRepository, io.Closer, error) {
+	seed := memory.DefaultTokens()
+
+	if cfg.TokensPath != "" {
+		var err error
+
+		seed, err = memory.LoadTokens(cfg.TokensPath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	switch cfg.Type {
+	case "", "memory":
+		repo, err := memory.NewCurrencyRateRepoFromTokens(seed)
+
+		return repo, nil, err
+	case "sqlite":
+		if cfg.Path == "" {
+			return nil, nil, errors.New("sqlite repository requires a Path")
+		}
+
+		repo, err := sqlite.Open(cfg.Path, seed)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return repo, repo, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown repository type %q", cfg.Type)
+	}
+}
+
+func newAlertEngine(
+	cfg configuration.Alerts,
+	updates alerts.Updates,
+	repo repository.CurrencyRate,
+) (*alerts.Engine, error) {
+	secretEnv := cfg.SecretEnv
+	if secretEnv == "" {
+		secretEnv = "ALERTS_WEBHOOK_SECRET"
+	}
+
+	secret := os.Getenv(secretEnv)
+	if secret == "" {
+		return nil, fmt.Errorf("environment variable %s with the webhook signing secret is not set", secretEnv)
+	}
+
+	notifier := alerts.NewNotifier([]byte(secret), alerts.RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   cfg.RetryBaseDelayMs * time.Millisecond,
+	}, cfg.WebhookTimeout*time.Second, alerts.NewTargetPolicy(cfg.AllowedHosts))
+
+	return alerts.New(updates, repo, alerts.NewFileStore(cfg.StorePath), notifier, cfg.MaxDeadLetters)
 }
 
 func loadConfig() (configuration.Configuration, error) {
@@ -110,14 +394,6 @@ func loadConfig() (configuration.Configuration, error) {
 		slog.Info("No .env file found, using environment variables...")
 	}
 
-	appID := os.Getenv("APP_ID")
-	if appID == "" {
-		return configuration.Configuration{},
-			errors.New("APP_ID is required for openExchangeAPI access")
-	}
-
-	slog.Info("openExchangeAPI configured", slog.String("appID", appID))
-
 	var cfg configuration.Configuration
 
 	err = configuration.GetConfig("./config", &cfg)
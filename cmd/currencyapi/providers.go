@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/api"
+	"main/internal/api/breaker"
+	"main/internal/api/ecb"
+	"main/internal/api/failover"
+	"main/internal/api/file"
+	openExchange "main/internal/api/openexchange"
+	"main/internal/api/quota"
+	"main/internal/api/recorder"
+	"main/internal/chaos"
+	"main/internal/configuration"
+	"main/internal/handlers/health"
+	"main/internal/handlers/usage"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	openExchangeProvider = "openexchange"
+	ecbProvider          = "ecb"
+	fileProvider         = "file"
+	defaultAppIDEnv      = "APP_ID"
+)
+
+type upstream struct {
+	chain         *failover.Failover
+	currencyNames api.CurrencyNames
+	breakers      map[string]health.CircuitBreaker
+	budgets       map[string]usage.Budget
+	injector      *chaos.Injector
+	workers       []worker
+}
+
+func newUpstream(cfg configuration.Configuration) (upstream, error) {
+	providers := make([]failover.Provider, 0, len(cfg.Providers))
+	breakers := make(map[string]health.CircuitBreaker)
+	budgets := make(map[string]usage.Budget)
+
+	var (
+		currencyNames api.CurrencyNames
+		workers       []worker
+	)
+
+	injector, err := newInjector(cfg.Chaos)
+	if err != nil {
+		return upstream{}, err
+	}
+
+	for _, providerCfg := range cfg.Providers {
+		provider, err := newProvider(providerCfg)
+		if err != nil {
+			return upstream{}, fmt.Errorf("error preparing provider %s: %w", providerCfg.Name, err)
+		}
+
+		if circuitBreaker, ok := provider.(health.CircuitBreaker); ok {
+			breakers[providerCfg.Name] = circuitBreaker
+		}
+
+		if watcher, ok := provider.(*file.File); ok && providerCfg.WatchInterval > 0 {
+			workers = append(workers, watcher)
+		}
+
+		_, hasNames := provider.(api.CurrencyNames)
+
+		reporter, ok := provider.(api.UsageReporter)
+		if ok && providerCfg.Quota.CheckInterval > 0 {
+			budget := quota.New(
+				provider,
+				reporter,
+				providerCfg.Quota.CheckInterval*time.Second,
+				providerCfg.Quota.WarnThresholds,
+			)
+
+			budgets[providerCfg.Name] = budget
+			workers = append(workers, budget)
+			provider = budget
+		}
+
+		// Taken after the budget, so the names are fetched within the quota too.
+		if names, ok := provider.(api.CurrencyNames); ok && hasNames && currencyNames == nil {
+			currencyNames = names
+		}
+
+		providers = append(providers, failover.Provider{
+			Name:    providerCfg.Name,
+			API:     provider,
+			Timeout: providerCfg.Timeout * time.Second,
+		})
+	}
+
+	chain, err := failover.New(providers...)
+	if err != nil {
+		return upstream{}, fmt.Errorf("error preparing provider chain: %w", err)
+	}
+
+	return upstream{
+		chain:         chain,
+		currencyNames: currencyNames,
+		breakers:      breakers,
+		budgets:       budgets,
+		injector:      injector,
+		workers:       workers,
+	}, nil
+}
+
+// newInjector returns nil when no faults are configured, nothing is wrapped then.
+func newInjector(cfg configuration.Chaos) (*chaos.Injector, error) {
+	if len(cfg.Faults) == 0 {
+		return nil, nil
+	}
+
+	injector, err := chaos.NewInjector(chaos.Settings{
+		Enabled:   cfg.Enabled,
+		Fraction:  cfg.Fraction,
+		LatencyMs: cfg.LatencyMs,
+		Faults:    cfg.Faults,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error preparing chaos: %w", err)
+	}
+
+	return injector, nil
+}
+
+func newProvider(cfg configuration.Provider) (api.CurrencyRate, error) {
+	switch cfg.Type {
+	case openExchangeProvider:
+		appIDEnv := cfg.AppIDEnv
+		if appIDEnv == "" {
+			appIDEnv = defaultAppIDEnv
+		}
+
+		// Replayed responses need no credentials.
+		appID := os.Getenv(appIDEnv)
+		if appID == "" && cfg.Fixtures.Mode != recorder.ModeReplay {
+			return nil, fmt.Errorf("%s is required for openExchangeAPI access", appIDEnv)
+		}
+
+		opts, err := openExchangeOptions(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return openExchange.New(cfg.APIURL, appID, opts...)
+	case ecbProvider:
+		return ecb.New(cfg.APIURL, cfg.Base)
+	case fileProvider:
+		return file.New(cfg.Path, cfg.Base, cfg.WatchInterval*time.Second)
+	case "":
+		return nil, errors.New("provider type is required")
+	default:
+		return nil, fmt.Errorf("unknown provider type %q", cfg.Type)
+	}
+}
+
+func openExchangeOptions(cfg configuration.Provider) ([]openExchange.Option, error) {
+	opts := []openExchange.Option{
+		openExchange.WithRetry(openExchange.RetryPolicy{
+			MaxRetries: cfg.Retry.MaxRetries,
+			BaseDelay:  cfg.Retry.BaseDelayMs * time.Millisecond,
+			MaxDelay:   cfg.Retry.MaxDelayMs * time.Millisecond,
+		}),
+	}
+
+	if cfg.Base != "" {
+		opts = append(opts, openExchange.WithBase(cfg.Base))
+	}
+
+	if cfg.Symbols {
+		opts = append(opts, openExchange.WithSymbols())
+	}
+
+	if cfg.CircuitBreaker.Threshold > 0 {
+		opts = append(opts, openExchange.WithCircuitBreaker(
+			breaker.New(cfg.CircuitBreaker.Threshold, cfg.CircuitBreaker.Cooldown*time.Second),
+		))
+	}
+
+	if cfg.Fixtures.Mode != "" {
+		transport, err := recorder.New(cfg.Fixtures.Mode, cfg.Fixtures.Dir, http.DefaultTransport)
+		if err != nil {
+			return nil, fmt.Errorf("error preparing fixtures: %w", err)
+		}
+
+		opts = append(opts, openExchange.WithHTTPClient(&http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout * time.Second,
+		}))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// worker is a background job living as long as the server does.
+type worker interface {
+	Run(ctx context.Context)
+}
+
+func startWorkers(ctx context.Context, workers []worker) *sync.WaitGroup {
+	var wg sync.WaitGroup
+
+	for _, w := range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			w.Run(ctx)
+		}()
+	}
+
+	return &wg
+}
+
+// waitWorkers waits for the workers to finish, but not longer than ctx allows.
+func waitWorkers(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"main/internal/fakeoxr"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+func main() {
+	gin.SetMode(gin.ReleaseMode)
+
+	addr := flag.String("addr", ":8081", "listen address")
+	appID := flag.String("app-id", "", "the only accepted app_id, any when empty")
+	ratesFile := flag.String("rates", "", "latest.json formatted file with the USD quoted starting rates")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	errorRates := flag.String("errors", "", "error probabilities, e.g. 401=0.01,429=0.05,500=0.02,503=0.02,malformed=0.01")
+	volatility := flag.Float64("volatility", 0.001, "standard deviation of the relative rate change per walk step")
+	walkInterval := flag.Duration("walk", 5*time.Second, "how often rates move, 0 freezes them")
+	allowBase := flag.Bool("allow-base", false, "allow the base parameter like paid plans do")
+	allowSymbols := flag.Bool("allow-symbols", false, "allow the symbols parameter like paid plans do")
+	quota := flag.Int("quota", 0, "monthly requests quota, 0 is unlimited")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "random seed for reproducible runs")
+	flag.Parse()
+
+	cfg := fakeoxr.Config{
+		AppID:         *appID,
+		Latency:       *latency,
+		Volatility:    *volatility,
+		WalkInterval:  *walkInterval,
+		AllowBase:     *allowBase,
+		AllowSymbols:  *allowSymbols,
+		RequestsQuota: *quota,
+		Seed:          *seed,
+	}
+
+	var err error
+
+	cfg.Errors, err = parseErrors(*errorRates)
+	if err != nil {
+		slog.Error("Invalid -errors flag", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if *ratesFile != "" {
+		cfg.Rates, err = loadRates(*ratesFile)
+		if err != nil {
+			slog.Error("Invalid -rates file", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	run(*addr, fakeoxr.New(cfg))
+}
+
+func run(addr string, server *fakeoxr.Server) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	go server.Run(ctx)
+
+	go func() {
+		slog.Info("Starting fake openexchangerates server...", slog.String("address", addr))
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("server error", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server forced to shutdown", slog.String("err", err.Error()))
+	}
+}
+
+func parseErrors(param string) (map[string]float64, error) {
+	errorRates := make(map[string]float64)
+
+	if param == "" {
+		return errorRates, nil
+	}
+
+	var total float64
+
+	for _, pair := range strings.Split(param, ",") {
+		kind, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected kind=probability, got %q", pair)
+		}
+
+		switch kind {
+		case fakeoxr.ErrorUnauthorized, fakeoxr.ErrorRateLimit, fakeoxr.ErrorServer,
+			fakeoxr.ErrorUnavailable, fakeoxr.ErrorMalformed:
+		default:
+			return nil, fmt.Errorf("unknown error kind %q", kind)
+		}
+
+		probability, err := strconv.ParseFloat(value, 64)
+		if err != nil || probability < 0 {
+			return nil, fmt.Errorf("invalid probability %q for %s", value, kind)
+		}
+
+		errorRates[kind] = probability
+		total += probability
+	}
+
+	if total > 1 {
+		return nil, fmt.Errorf("error probabilities add up to %v, more than 1", total)
+	}
+
+	return errorRates, nil
+}
+
+func loadRates(path string) (map[string]decimal.Decimal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var table struct {
+		Rates map[string]decimal.Decimal `json:"rates"`
+	}
+
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	return table.Rates, nil
+}
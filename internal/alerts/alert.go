@@ -0,0 +1,98 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"main/internal/errs"
+	"net/url"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// ConditionCrosses fires every time the rate moves past Threshold, in either direction.
+	ConditionCrosses = "crosses"
+	// ConditionChange fires when the rate moves by more than Percent within Window.
+	ConditionChange = "change"
+)
+
+type Alert struct {
+	ID              string           `json:"id"`
+	From            string           `json:"from"`
+	To              string           `json:"to"`
+	Condition       string           `json:"condition"`
+	Threshold       *decimal.Decimal `json:"threshold,omitempty"`
+	Percent         *decimal.Decimal `json:"percent,omitempty"`
+	Window          string           `json:"window,omitempty"`
+	WebhookURL      string           `json:"webhookUrl"`
+	CreatedAt       time.Time        `json:"createdAt"`
+	LastTriggeredAt *time.Time       `json:"lastTriggeredAt,omitempty"`
+}
+
+// Event is the body of the webhook sent when an alert fires.
+type Event struct {
+	ID            string           `json:"id"`
+	AlertID       string           `json:"alertId"`
+	Condition     string           `json:"condition"`
+	From          string           `json:"from"`
+	To            string           `json:"to"`
+	Rate          json.Number      `json:"rate"`
+	PreviousRate  json.Number      `json:"previousRate"`
+	Direction     string           `json:"direction"`
+	Threshold     *decimal.Decimal `json:"threshold,omitempty"`
+	Percent       *decimal.Decimal `json:"percent,omitempty"`
+	ChangePercent json.Number      `json:"changePercent,omitempty"`
+	TriggeredAt   time.Time        `json:"triggeredAt"`
+}
+
+// DeadLetter is an event whose webhook could not be delivered.
+type DeadLetter struct {
+	Event      Event     `json:"event"`
+	WebhookURL string    `json:"webhookUrl"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"lastError"`
+	FailedAt   time.Time `json:"failedAt"`
+}
+
+func (a Alert) validate() error {
+	if a.From == "" || a.To == "" {
+		return fmt.Errorf("%w: from and to are required", errs.ErrInvalidAlert)
+	}
+
+	if a.From == a.To {
+		return fmt.Errorf("%w: from and to must differ", errs.ErrInvalidAlert)
+	}
+
+	webhook, err := url.Parse(a.WebhookURL)
+	if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Host == "" {
+		return fmt.Errorf("%w: webhookUrl must be an absolute http(s) url", errs.ErrInvalidAlert)
+	}
+
+	switch a.Condition {
+	case ConditionCrosses:
+		if a.Threshold == nil || !a.Threshold.IsPositive() {
+			return fmt.Errorf("%w: threshold must be a positive number", errs.ErrInvalidAlert)
+		}
+	case ConditionChange:
+		if a.Percent == nil || !a.Percent.IsPositive() {
+			return fmt.Errorf("%w: percent must be a positive number", errs.ErrInvalidAlert)
+		}
+
+		window, err := time.ParseDuration(a.Window)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("%w: window must be a positive duration like 1h", errs.ErrInvalidAlert)
+		}
+	default:
+		return fmt.Errorf("%w: condition must be %s or %s", errs.ErrInvalidAlert, ConditionCrosses, ConditionChange)
+	}
+
+	return nil
+}
+
+// window is only called on validated alerts.
+func (a Alert) window() time.Duration {
+	window, _ := time.ParseDuration(a.Window)
+
+	return window
+}
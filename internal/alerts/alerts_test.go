@@ -0,0 +1,479 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/repository/memory"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var testSecret = []byte("secret")
+
+type MockUpdates struct {
+	updates chan api.Response
+}
+
+func (m *MockUpdates) Subscribe() (<-chan api.Response, func()) {
+	return m.updates, func() {}
+}
+
+type MockCurrencyRateRepo struct {
+	details map[string]memory.CurrencyDetails
+}
+
+func (m *MockCurrencyRateRepo) Get(currency string) (memory.CurrencyDetails, error) {
+	details, ok := m.details[currency]
+	if !ok {
+		return memory.CurrencyDetails{}, errs.ErrRepoCurrencyNotFound
+	}
+
+	return details, nil
+}
+
+func (m *MockCurrencyRateRepo) set(currency, rate string) {
+	m.details[currency] = memory.CurrencyDetails{DecimalPrecision: 6, Rate: decimal.RequireFromString(rate)}
+}
+
+func table(rates map[string]string) api.Response {
+	resp := api.Response{Base: "USD", Rates: make(map[string]decimal.Decimal, len(rates))}
+
+	for currency, rate := range rates {
+		resp.Rates[currency] = decimal.RequireFromString(rate)
+	}
+
+	return resp
+}
+
+// testPolicy lets the tests reach their local receivers.
+var testPolicy = NewTargetPolicy([]string{"localhost", "127.0.0.1"})
+
+func ptr(value string) *decimal.Decimal {
+	d := decimal.RequireFromString(value)
+
+	return &d
+}
+
+func newTestEngine(t *testing.T, store Store, notifier *Notifier) *Engine {
+	t.Helper()
+
+	if notifier == nil {
+		notifier = NewNotifier(testSecret, RetryPolicy{MaxAttempts: 1}, time.Second, testPolicy)
+	}
+
+	engine, err := New(&MockUpdates{updates: make(chan api.Response)}, memory.NewCurrencyRateRepo(), store, notifier, 0)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	return engine
+}
+
+func TestAlert_Validate(t *testing.T) {
+	valid := Alert{From: "EUR", To: "PLN", Condition: ConditionCrosses, Threshold: ptr("4.30"), WebhookURL: "https://example.com/hook"}
+
+	tests := []struct {
+		name    string
+		modify  func(a *Alert)
+		wantErr bool
+	}{
+		{name: "crosses", modify: func(*Alert) {}},
+		{name: "change", modify: func(a *Alert) {
+			a.Condition, a.Threshold, a.Percent, a.Window = ConditionChange, nil, ptr("5"), "1h"
+		}},
+		{name: "same currencies", modify: func(a *Alert) { a.To = "EUR" }, wantErr: true},
+		{name: "missing currency", modify: func(a *Alert) { a.From = "" }, wantErr: true},
+		{name: "unknown condition", modify: func(a *Alert) { a.Condition = "above" }, wantErr: true},
+		{name: "missing threshold", modify: func(a *Alert) { a.Threshold = nil }, wantErr: true},
+		{name: "negative threshold", modify: func(a *Alert) { a.Threshold = ptr("-1") }, wantErr: true},
+		{name: "relative webhook", modify: func(a *Alert) { a.WebhookURL = "/hook" }, wantErr: true},
+		{name: "bad window", modify: func(a *Alert) {
+			a.Condition, a.Percent, a.Window = ConditionChange, ptr("5"), "hour"
+		}, wantErr: true},
+		{name: "zero percent", modify: func(a *Alert) {
+			a.Condition, a.Percent, a.Window = ConditionChange, ptr("0"), "1h"
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := valid
+			tt.modify(&alert)
+
+			err := alert.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, errs.ErrInvalidAlert) {
+				t.Errorf("validate() error = %v, want %v", err, errs.ErrInvalidAlert)
+			}
+		})
+	}
+}
+
+func TestTargetPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		webhookURL string
+		allowed    []string
+		wantErr    bool
+	}{
+		{name: "public host", webhookURL: "https://example.com/hook"},
+		{name: "public address", webhookURL: "http://93.184.216.34/hook"},
+		{name: "localhost", webhookURL: "http://localhost:8080/admin/chaos", wantErr: true},
+		{name: "localhost subdomain", webhookURL: "http://api.localhost/hook", wantErr: true},
+		{name: "loopback", webhookURL: "http://127.0.0.1/hook", wantErr: true},
+		{name: "loopback ipv6", webhookURL: "http://[::1]/hook", wantErr: true},
+		{name: "metadata service", webhookURL: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "private network", webhookURL: "http://10.0.0.1/hook", wantErr: true},
+		{name: "unspecified", webhookURL: "http://0.0.0.0/hook", wantErr: true},
+		{name: "allowed host", webhookURL: "http://LocalHost:9000/hook", allowed: []string{"localhost"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewTargetPolicy(tt.allowed).check(tt.webhookURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, errs.ErrInvalidAlert) {
+				t.Errorf("check() error = %v, want %v", err, errs.ErrInvalidAlert)
+			}
+		})
+	}
+}
+
+func TestNotifier_BlocksInternalTargets(t *testing.T) {
+	var calls atomic.Int32
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	// A public name may still resolve to an internal address, the dialer checks every connection.
+	notifier := NewNotifier(testSecret, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, time.Second, NewTargetPolicy(nil))
+
+	attempts, err := notifier.Deliver(context.Background(), receiver.URL, Event{ID: "event"})
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("Deliver() error = %v, want %v", err, ErrForbiddenTarget)
+	}
+
+	if attempts != 1 || calls.Load() != 0 {
+		t.Errorf("Deliver() attempts = %d, webhook calls = %d, want 1 attempt and no call", attempts, calls.Load())
+	}
+}
+
+func TestEngine_Crosses(t *testing.T) {
+	engine := newTestEngine(t, NewFileStore(filepath.Join(t.TempDir(), "alerts.json")), nil)
+
+	alert, err := engine.Create(Alert{
+		From:       "EUR",
+		To:         "PLN",
+		Condition:  ConditionCrosses,
+		Threshold:  ptr("4.30"),
+		WebhookURL: "http://localhost/hook",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	steps := []struct {
+		pln           string
+		wantDirection string
+	}{
+		{pln: "4.28"},
+		{pln: "4.29"},
+		{pln: "4.31", wantDirection: directionUp},
+		{pln: "4.32"},
+		{pln: "4.30", wantDirection: directionDown},
+		{pln: "4.30"},
+		{pln: "4.31"},
+	}
+
+	for i, step := range steps {
+		fired := engine.evaluate(table(map[string]string{"USD": "1", "EUR": "1", "PLN": step.pln}))
+
+		if step.wantDirection == "" {
+			if len(fired) != 0 {
+				t.Errorf("step %d: evaluate() fired %+v, want nothing", i, fired)
+			}
+
+			continue
+		}
+
+		if len(fired) != 1 {
+			t.Fatalf("step %d: evaluate() fired %d events, want 1", i, len(fired))
+		}
+
+		event := fired[0].event
+		if event.AlertID != alert.ID || event.Direction != step.wantDirection {
+			t.Errorf("step %d: evaluate() got = %+v, want direction %s", i, event, step.wantDirection)
+		}
+
+		if event.Rate != json.Number(step.pln+"000000") {
+			t.Errorf("step %d: evaluate() rate got = %v, want %v", i, event.Rate, step.pln)
+		}
+	}
+
+	got, _ := engine.Get(alert.ID)
+	if got.LastTriggeredAt == nil {
+		t.Errorf("Get() LastTriggeredAt got = nil, want the last firing")
+	}
+}
+
+func TestEngine_Change(t *testing.T) {
+	engine := newTestEngine(t, NewFileStore(filepath.Join(t.TempDir(), "alerts.json")), nil)
+
+	repo := &MockCurrencyRateRepo{details: map[string]memory.CurrencyDetails{}}
+	repo.set("WBTC", "100")
+	repo.set("USDT", "1")
+	engine.repo = repo
+
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	// WBTC is not in the rate table, so it is priced with the token repository.
+	_, err := engine.Create(Alert{
+		From:       "WBTC",
+		To:         "USDT",
+		Condition:  ConditionChange,
+		Percent:    ptr("5"),
+		Window:     "1h",
+		WebhookURL: "http://localhost/hook",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	current := table(map[string]string{"USD": "1"})
+
+	if fired := engine.evaluate(current); len(fired) != 0 {
+		t.Fatalf("evaluate() fired %+v on the first observation", fired)
+	}
+
+	// 4% within the window does not fire.
+	now = now.Add(30 * time.Minute)
+	repo.set("WBTC", "104")
+
+	if fired := engine.evaluate(current); len(fired) != 0 {
+		t.Fatalf("evaluate() fired %+v on a 4%% move", fired)
+	}
+
+	// 6% from a reference older than the window does not fire, the window slid past it.
+	now = now.Add(45 * time.Minute)
+	repo.set("WBTC", "106")
+
+	if fired := engine.evaluate(current); len(fired) != 0 {
+		t.Fatalf("evaluate() fired %+v on a move outside the window", fired)
+	}
+
+	now = now.Add(10 * time.Minute)
+	repo.set("WBTC", "97")
+
+	fired := engine.evaluate(current)
+	if len(fired) != 1 {
+		t.Fatalf("evaluate() fired %d events, want 1", len(fired))
+	}
+
+	if fired[0].event.Direction != directionDown || fired[0].event.ChangePercent != "-6.73" {
+		t.Errorf("evaluate() got = %+v, want a -6.73%% move down", fired[0].event)
+	}
+
+	// The window starts over after firing.
+	now = now.Add(time.Minute)
+
+	if fired := engine.evaluate(current); len(fired) != 0 {
+		t.Errorf("evaluate() fired %+v twice for the same move", fired)
+	}
+}
+
+func TestEngine_PersistsAcrossRestarts(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "nested", "alerts.json"))
+
+	engine := newTestEngine(t, store, nil)
+
+	alert, err := engine.Create(Alert{
+		From:       "EUR",
+		To:         "PLN",
+		Condition:  ConditionCrosses,
+		Threshold:  ptr("4.30"),
+		WebhookURL: "http://localhost/hook",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	other, err := engine.Create(Alert{
+		From:       "USD",
+		To:         "EUR",
+		Condition:  ConditionCrosses,
+		Threshold:  ptr("0.9"),
+		WebhookURL: "http://localhost/hook",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := engine.Delete(other.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if err := engine.Delete(other.ID); !errors.Is(err, errs.ErrAlertNotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, errs.ErrAlertNotFound)
+	}
+
+	restarted := newTestEngine(t, store, nil)
+
+	got := restarted.List()
+	if len(got) != 1 || got[0].ID != alert.ID || !got[0].Threshold.Equal(*alert.Threshold) {
+		t.Errorf("List() got = %+v, want [%+v]", got, alert)
+	}
+}
+
+func TestEngine_DeliversSignedWebhooks(t *testing.T) {
+	var calls atomic.Int32
+
+	received := make(chan Event, 1)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		signature := r.Header.Get(SignatureHeader)
+
+		timestamp, _, _ := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			t.Errorf("webhook signature %q error = %v", signature, err)
+		}
+
+		sent := time.Unix(unix, 0)
+
+		if signature != Sign(testSecret, sent, body) {
+			t.Errorf("webhook signature got = %s, want %s", signature, Sign(testSecret, sent, body))
+		}
+
+		// The first two attempts fail and are retried.
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("webhook body error = %v", err)
+		}
+
+		received <- event
+	}))
+	defer receiver.Close()
+
+	updates := &MockUpdates{updates: make(chan api.Response)}
+	notifier := NewNotifier(testSecret, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, time.Second, testPolicy)
+
+	engine, err := New(updates, nil, NewFileStore(filepath.Join(t.TempDir(), "alerts.json")), notifier, 0)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	alert, err := engine.Create(Alert{
+		From:       "EUR",
+		To:         "PLN",
+		Condition:  ConditionCrosses,
+		Threshold:  ptr("4.30"),
+		WebhookURL: receiver.URL,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		engine.Run(ctx)
+		close(done)
+	}()
+
+	updates.updates <- table(map[string]string{"EUR": "0.5", "PLN": "2.1"})
+	updates.updates <- table(map[string]string{"EUR": "0.5", "PLN": "2.2"})
+
+	select {
+	case event := <-received:
+		if event.AlertID != alert.ID || event.Rate != "4.40000000" || event.PreviousRate != "4.20000000" {
+			t.Errorf("webhook event got = %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	// Shutdown would cut the delivery still waiting for the response short.
+	engine.deliveries.Wait()
+	cancel()
+	<-done
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("webhook calls got = %d, want 3", got)
+	}
+
+	if got := engine.DeadLetters(); len(got) != 0 {
+		t.Errorf("DeadLetters() got = %+v, want none", got)
+	}
+}
+
+func TestEngine_DeadLetters(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantAttempts int
+	}{
+		{name: "server errors exhaust the retries", status: http.StatusInternalServerError, wantAttempts: 3},
+		{name: "client errors are not retried", status: http.StatusBadRequest, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			store := NewFileStore(filepath.Join(t.TempDir(), "alerts.json"))
+			notifier := NewNotifier(testSecret, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, time.Second, testPolicy)
+			engine := newTestEngine(t, store, notifier)
+
+			event := Event{ID: "event", AlertID: "alert"}
+			engine.deliver(context.Background(), pending{webhookURL: receiver.URL, event: event})
+
+			if got := int(calls.Load()); got != tt.wantAttempts {
+				t.Errorf("webhook calls got = %d, want %d", got, tt.wantAttempts)
+			}
+
+			restarted := newTestEngine(t, store, nil)
+
+			got := restarted.DeadLetters()
+			if len(got) != 1 || got[0].Event.ID != event.ID || got[0].Attempts != tt.wantAttempts {
+				t.Errorf("DeadLetters() got = %+v, want the event after %d attempts", got, tt.wantAttempts)
+			}
+		})
+	}
+}
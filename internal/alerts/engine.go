@@ -0,0 +1,397 @@
+package alerts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/handlers/exchange"
+	"main/internal/handlers/rates"
+	"main/internal/repository"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	directionUp   = "up"
+	directionDown = "down"
+
+	defaultMaxDeadLetters = 1000
+)
+
+// Updates notifies about every change of the rate table.
+type Updates interface {
+	Subscribe() (<-chan api.Response, func())
+}
+
+type observation struct {
+	at     time.Time
+	rate   decimal.Decimal
+	number json.Number
+}
+
+type pending struct {
+	webhookURL string
+	event      Event
+}
+
+// Engine evaluates the alerts against every new rate table and delivers the ones that fire.
+// Pairs missing from the rate table are priced with the token repository, like /exchange does.
+type Engine struct {
+	updates        Updates
+	repo           repository.CurrencyRate
+	store          Store
+	notifier       *Notifier
+	maxDeadLetters int
+
+	mu    sync.Mutex
+	state State
+	// last and history are kept in memory only, a restart starts observing afresh.
+	last    map[string]observation
+	history map[string][]observation
+
+	deliveries sync.WaitGroup
+	now        func() time.Time
+}
+
+func New(
+	updates Updates,
+	repo repository.CurrencyRate,
+	store Store,
+	notifier *Notifier,
+	maxDeadLetters int,
+) (*Engine, error) {
+	state, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if maxDeadLetters <= 0 {
+		maxDeadLetters = defaultMaxDeadLetters
+	}
+
+	return &Engine{
+		updates:        updates,
+		repo:           repo,
+		store:          store,
+		notifier:       notifier,
+		maxDeadLetters: maxDeadLetters,
+		state:          state,
+		last:           make(map[string]observation),
+		history:        make(map[string][]observation),
+		now:            time.Now,
+	}, nil
+}
+
+// Run evaluates the alerts until ctx is done, then waits for the deliveries in flight.
+// Those are cut short and end up in the dead-letter list.
+func (e *Engine) Run(ctx context.Context) {
+	updates, cancel := e.updates.Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.deliveries.Wait()
+
+			return
+		case table := <-updates:
+			for _, p := range e.evaluate(table) {
+				e.deliveries.Add(1)
+
+				go func() {
+					defer e.deliveries.Done()
+
+					e.deliver(ctx, p)
+				}()
+			}
+		}
+	}
+}
+
+func (e *Engine) Create(alert Alert) (Alert, error) {
+	if err := alert.validate(); err != nil {
+		return Alert{}, err
+	}
+
+	if err := e.notifier.Check(alert.WebhookURL); err != nil {
+		return Alert{}, err
+	}
+
+	alert.ID = newID()
+	alert.CreatedAt = e.now().UTC()
+	alert.LastTriggeredAt = nil
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state := e.state
+	state.Alerts = append(slices.Clone(state.Alerts), alert)
+
+	if err := e.store.Save(state); err != nil {
+		return Alert{}, err
+	}
+
+	e.state = state
+
+	return alert, nil
+}
+
+func (e *Engine) List() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.state.Alerts)
+}
+
+func (e *Engine) Get(id string) (Alert, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	i := e.index(id)
+	if i < 0 {
+		return Alert{}, errs.ErrAlertNotFound
+	}
+
+	return e.state.Alerts[i], nil
+}
+
+func (e *Engine) Delete(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	i := e.index(id)
+	if i < 0 {
+		return errs.ErrAlertNotFound
+	}
+
+	state := e.state
+	state.Alerts = slices.Delete(slices.Clone(state.Alerts), i, i+1)
+
+	if err := e.store.Save(state); err != nil {
+		return err
+	}
+
+	e.state = state
+
+	delete(e.last, id)
+	delete(e.history, id)
+
+	return nil
+}
+
+func (e *Engine) DeadLetters() []DeadLetter {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.state.DeadLetters)
+}
+
+func (e *Engine) index(id string) int {
+	return slices.IndexFunc(e.state.Alerts, func(a Alert) bool {
+		return a.ID == id
+	})
+}
+
+func (e *Engine) evaluate(table api.Response) []pending {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now().UTC()
+
+	var fired []pending
+
+	for i := range e.state.Alerts {
+		alert := &e.state.Alerts[i]
+
+		current, err := e.price(table, alert.From, alert.To)
+		if err != nil {
+			continue
+		}
+
+		current.at = now
+
+		var (
+			event Event
+			ok    bool
+		)
+
+		switch alert.Condition {
+		case ConditionCrosses:
+			event, ok = e.crosses(*alert, current)
+		case ConditionChange:
+			event, ok = e.change(*alert, current)
+		}
+
+		if !ok {
+			continue
+		}
+
+		event.ID = newID()
+		event.AlertID = alert.ID
+		event.Condition = alert.Condition
+		event.From = alert.From
+		event.To = alert.To
+		event.Rate = current.number
+		event.TriggeredAt = now
+
+		alert.LastTriggeredAt = &now
+
+		fired = append(fired, pending{webhookURL: alert.WebhookURL, event: event})
+	}
+
+	if len(fired) > 0 {
+		if err := e.store.Save(e.state); err != nil {
+			slog.Error("failed to save alerts", slog.String("err", err.Error()))
+		}
+	}
+
+	return fired
+}
+
+func (e *Engine) crosses(alert Alert, current observation) (Event, bool) {
+	previous, seen := e.last[alert.ID]
+	e.last[alert.ID] = current
+
+	if !seen {
+		return Event{}, false
+	}
+
+	threshold := *alert.Threshold
+
+	var direction string
+
+	switch {
+	case previous.rate.LessThan(threshold) && current.rate.GreaterThanOrEqual(threshold):
+		direction = directionUp
+	case previous.rate.GreaterThan(threshold) && current.rate.LessThanOrEqual(threshold):
+		direction = directionDown
+	default:
+		return Event{}, false
+	}
+
+	return Event{
+		PreviousRate: previous.number,
+		Direction:    direction,
+		Threshold:    alert.Threshold,
+	}, true
+}
+
+// change compares the rate with the oldest one seen within the window. Once fired,
+// the window starts over, so a single move is reported once.
+func (e *Engine) change(alert Alert, current observation) (Event, bool) {
+	cutoff := current.at.Add(-alert.window())
+
+	observed := slices.DeleteFunc(e.history[alert.ID], func(o observation) bool {
+		return o.at.Before(cutoff)
+	})
+	observed = append(observed, current)
+
+	reference := observed[0]
+	if reference.rate.IsZero() {
+		e.history[alert.ID] = []observation{current}
+
+		return Event{}, false
+	}
+
+	change := current.rate.Sub(reference.rate).Div(reference.rate).Mul(decimal.NewFromInt(100))
+	if change.Abs().LessThan(*alert.Percent) {
+		e.history[alert.ID] = observed
+
+		return Event{}, false
+	}
+
+	e.history[alert.ID] = []observation{current}
+
+	direction := directionUp
+	if change.IsNegative() {
+		direction = directionDown
+	}
+
+	return Event{
+		PreviousRate:  reference.number,
+		Direction:     direction,
+		Percent:       alert.Percent,
+		ChangePercent: json.Number(change.StringFixed(2)),
+	}, true
+}
+
+func (e *Engine) price(table api.Response, from, to string) (observation, error) {
+	_, hasFrom := table.Rates[from]
+	_, hasTo := table.Rates[to]
+
+	var number json.Number
+
+	if hasFrom && hasTo {
+		pairs, err := rates.Calculate(table.Rates, []string{from, to})
+		if err != nil {
+			return observation{}, err
+		}
+
+		number = pairs[0].Rate
+	} else {
+		if e.repo == nil {
+			return observation{}, errs.ErrCurrencyNotFound
+		}
+
+		quote, err := exchange.Quote(e.repo, from, to, decimal.NewFromInt(1))
+		if err != nil {
+			return observation{}, err
+		}
+
+		number = quote.Amount
+	}
+
+	rate, err := decimal.NewFromString(number.String())
+	if err != nil {
+		return observation{}, fmt.Errorf("error parsing rate %s: %w", number, err)
+	}
+
+	return observation{rate: rate, number: number}, nil
+}
+
+func (e *Engine) deliver(ctx context.Context, p pending) {
+	attempts, err := e.notifier.Deliver(ctx, p.webhookURL, p.event)
+	if err == nil {
+		return
+	}
+
+	slog.Warn("alert webhook failed",
+		slog.String("alert", p.event.AlertID),
+		slog.Int("attempts", attempts),
+		slog.String("err", err.Error()),
+	)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.state.DeadLetters = append(e.state.DeadLetters, DeadLetter{
+		Event:      p.event,
+		WebhookURL: p.webhookURL,
+		Attempts:   attempts,
+		LastError:  err.Error(),
+		FailedAt:   e.now().UTC(),
+	})
+
+	if excess := len(e.state.DeadLetters) - e.maxDeadLetters; excess > 0 {
+		e.state.DeadLetters = slices.Delete(e.state.DeadLetters, 0, excess)
+	}
+
+	if err := e.store.Save(e.state); err != nil {
+		slog.Error("failed to save alerts", slog.String("err", err.Error()))
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
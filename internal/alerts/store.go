@@ -0,0 +1,89 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// State is everything that has to survive a restart.
+type State struct {
+	Alerts      []Alert      `json:"alerts"`
+	DeadLetters []DeadLetter `json:"deadLetters"`
+}
+
+type Store interface {
+	Load() (State, error)
+	Save(state State) error
+}
+
+// FileStore keeps the state in a single JSON file.
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// Load returns an empty state when the file does not exist yet.
+func (f *FileStore) Load() (State, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return State{}, nil
+	}
+
+	if err != nil {
+		return State{}, fmt.Errorf("error reading alerts store %s: %w", f.path, err)
+	}
+
+	var state State
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("error parsing alerts store %s: %w", f.path, err)
+	}
+
+	return state, nil
+}
+
+// Save replaces the file atomically, a crash leaves either the old or the new state.
+func (f *FileStore) Save(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding alerts: %w", err)
+	}
+
+	dir := filepath.Dir(f.path)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating alerts store directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating alerts store: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("error writing alerts store: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing alerts store: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("error replacing alerts store %s: %w", f.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/errs"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+var ErrForbiddenTarget = errors.New("error webhook target is a loopback, link-local or private address")
+
+// TargetPolicy keeps webhooks away from the service's own network, anyone registering an alert
+// could otherwise make the service POST to internal endpoints. AllowedHosts are exempt.
+type TargetPolicy struct {
+	allowedHosts []string
+}
+
+func NewTargetPolicy(allowedHosts []string) TargetPolicy {
+	hosts := make([]string, 0, len(allowedHosts))
+	for _, host := range allowedHosts {
+		hosts = append(hosts, strings.ToLower(host))
+	}
+
+	return TargetPolicy{
+		allowedHosts: hosts,
+	}
+}
+
+func (p TargetPolicy) allows(host string) bool {
+	return slices.Contains(p.allowedHosts, strings.ToLower(host))
+}
+
+// check rejects the targets known to be internal before any request is sent.
+// Names resolving to internal addresses are caught when dialing.
+func (p TargetPolicy) check(webhookURL string) error {
+	target, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("%w: webhookUrl must be an absolute http(s) url", errs.ErrInvalidAlert)
+	}
+
+	host := strings.ToLower(target.Hostname())
+
+	if p.allows(host) {
+		return nil
+	}
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: webhookUrl must not point to a loopback, link-local or private address", errs.ErrInvalidAlert)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && forbiddenIP(ip) {
+		return fmt.Errorf("%w: webhookUrl must not point to a loopback, link-local or private address", errs.ErrInvalidAlert)
+	}
+
+	return nil
+}
+
+// dialControl runs for every connection of webhooks not in AllowedHosts, after name resolution.
+func (p TargetPolicy) dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || forbiddenIP(ip) {
+		return ErrForbiddenTarget
+	}
+
+	return nil
+}
+
+func forbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
@@ -0,0 +1,162 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Alert-Signature"
+
+	defaultMaxAttempts = 5
+	defaultBaseDelay   = time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+// Sign returns the signature header value for a body sent at timestamp:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">".
+// Receivers should recompute it and reject stale timestamps.
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// Notifier POSTs signed events to webhooks, retrying with exponential backoff.
+type Notifier struct {
+	// client can not reach internal addresses, trusted is used for the policy's AllowedHosts.
+	client  *http.Client
+	trusted *http.Client
+	policy  TargetPolicy
+	secret  []byte
+	retry   RetryPolicy
+	now     func() time.Time
+}
+
+func NewNotifier(secret []byte, retry RetryPolicy, timeout time.Duration, policy TargetPolicy) *Notifier {
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = defaultMaxAttempts
+	}
+
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = defaultBaseDelay
+	}
+
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	// No proxy, the dialer has to see the webhook's own address.
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: timeout,
+			Control: policy.dialControl,
+		}).DialContext,
+		TLSHandshakeTimeout: timeout,
+	}
+
+	return &Notifier{
+		client:  &http.Client{Timeout: timeout, Transport: transport, CheckRedirect: noRedirects},
+		trusted: &http.Client{Timeout: timeout, CheckRedirect: noRedirects},
+		policy:  policy,
+		secret:  secret,
+		retry:   retry,
+		now:     time.Now,
+	}
+}
+
+// Check tells whether webhookURL may be registered.
+func (n *Notifier) Check(webhookURL string) error {
+	return n.policy.check(webhookURL)
+}
+
+// noRedirects keeps a webhook from redirecting the request to where it could not be sent directly.
+func noRedirects(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// Deliver returns the number of attempts made and the last error when all of them failed.
+// Client errors other than 429 are not retried, the receiver will not change its mind.
+func (n *Notifier) Deliver(ctx context.Context, webhookURL string, event Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("error encoding event: %w", err)
+	}
+
+	var lastErr error
+
+	for attempt := 1; attempt <= n.retry.MaxAttempts; attempt++ {
+		retryable, err := n.post(ctx, webhookURL, body)
+		if err == nil {
+			return attempt, nil
+		}
+
+		lastErr = err
+
+		if !retryable || attempt == n.retry.MaxAttempts {
+			return attempt, lastErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("%w, last error: %w", ctx.Err(), lastErr)
+		case <-time.After(n.retry.BaseDelay << (attempt - 1)):
+		}
+	}
+
+	return n.retry.MaxAttempts, lastErr
+}
+
+func (n *Notifier) post(ctx context.Context, webhookURL string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("error creating webhook request %s: %w", webhookURL, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(n.secret, n.now(), body))
+
+	client := n.client
+	if n.policy.allows(req.URL.Hostname()) {
+		client = n.trusted
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return !errors.Is(err, ErrForbiddenTarget), fmt.Errorf("error sending webhook: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout
+
+	return retryable, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
@@ -1,13 +1,82 @@
 package api
 
-import "context"
+import (
+	"context"
+	"main/internal/errs"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type CurrencyRate interface {
 	GetCurrencyRates(ctx context.Context, currencies []string) (Response, error)
 }
 
+// BaseCurrencyRate is implemented by providers able to quote rates in a chosen base currency.
+type BaseCurrencyRate interface {
+	GetCurrencyRatesForBase(ctx context.Context, base string, currencies []string) (Response, error)
+}
+
+type HistoricalCurrencyRate interface {
+	GetHistoricalCurrencyRates(
+		ctx context.Context,
+		date time.Time,
+		currencies []string,
+	) (Response, error)
+}
+
+type CurrencyNames interface {
+	GetCurrencyNames(ctx context.Context) (map[string]string, error)
+}
+
+type UsageReporter interface {
+	GetUsage(ctx context.Context) (Usage, error)
+}
+
+// RequestCounter reports how many billed requests reached the upstream so far, retries included.
+type RequestCounter interface {
+	Requests() uint64
+}
+
+// Usage describes consumption of the provider plan in the current billing period.
+// RequestsQuota below zero means the plan is unlimited.
+type Usage struct {
+	Plan              string          `json:"plan"`
+	Requests          int             `json:"requests"`
+	RequestsQuota     int             `json:"requestsQuota"`
+	RequestsRemaining int             `json:"requestsRemaining"`
+	DaysElapsed       int             `json:"daysElapsed"`
+	DaysRemaining     int             `json:"daysRemaining"`
+	Features          map[string]bool `json:"features"`
+}
+
 type Response struct {
-	Rates     map[string]float64 `json:"rates"`
-	Base      string             `json:"base"`
-	Timestamp int                `json:"timestamp"`
+	Rates     map[string]decimal.Decimal `json:"rates"`
+	Base      string                     `json:"base"`
+	Timestamp int                        `json:"timestamp"`
+	// Provider names the failover chain member that served the table.
+	Provider string `json:"-"`
+}
+
+// Filter returns a copy of the response limited to the given currencies.
+// An empty list keeps the whole rate table.
+func (r Response) Filter(currencies []string) (Response, error) {
+	if len(currencies) == 0 {
+		return r, nil
+	}
+
+	neededCurrencies := make(map[string]decimal.Decimal, len(currencies))
+
+	for _, currency := range currencies {
+		val, ok := r.Rates[currency]
+		if !ok {
+			return Response{}, errs.ErrCurrencyNotFound
+		}
+
+		neededCurrencies[currency] = val
+	}
+
+	r.Rates = neededCurrencies
+
+	return r, nil
 }
@@ -0,0 +1,123 @@
+package breaker
+
+import (
+	"main/internal/errs"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half-open"
+)
+
+type Snapshot struct {
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+}
+
+// Breaker fails fast after threshold consecutive failures. Once the cooldown
+// passes it lets a single probe through and closes again if the probe succeeds.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     Closed,
+	}
+}
+
+// Allow reports errs.ErrCircuitOpen when the call should not reach the upstream.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		return nil
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return errs.ErrCircuitOpen
+		}
+
+		b.state = HalfOpen
+		b.probing = true
+
+		return nil
+	case HalfOpen:
+		if b.probing {
+			return errs.ErrCircuitOpen
+		}
+
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.probing = false
+	b.openedAt = time.Time{}
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = b.now()
+	}
+}
+
+// Release gives back a half-open probe slot when the call ended without
+// telling anything about upstream health, e.g. the caller gave up.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := Snapshot{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+
+	if b.state != Closed {
+		openedAt := b.openedAt
+		snapshot.OpenedAt = &openedAt
+	}
+
+	return snapshot
+}
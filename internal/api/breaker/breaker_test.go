@@ -0,0 +1,59 @@
+package breaker
+
+import (
+	"errors"
+	"main/internal/errs"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2025, 6, 18, 10, 0, 0, 0, time.UTC)
+
+	b := New(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after one failure got err = %v, want nil", err)
+	}
+
+	b.Failure()
+
+	if err := b.Allow(); !errors.Is(err, errs.ErrCircuitOpen) {
+		t.Fatalf("Allow() after threshold got err = %v, want %v", err, errs.ErrCircuitOpen)
+	}
+
+	now = now.Add(time.Minute)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after cooldown got err = %v, want nil", err)
+	}
+
+	if err := b.Allow(); !errors.Is(err, errs.ErrCircuitOpen) {
+		t.Fatalf("second Allow() while probing got err = %v, want %v", err, errs.ErrCircuitOpen)
+	}
+
+	if got := b.Snapshot().State; got != HalfOpen {
+		t.Fatalf("Snapshot() got state %s, want %s", got, HalfOpen)
+	}
+
+	b.Failure()
+
+	if got := b.Snapshot().State; got != Open {
+		t.Fatalf("failed probe got state %s, want %s", got, Open)
+	}
+
+	now = now.Add(time.Minute)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after second cooldown got err = %v, want nil", err)
+	}
+
+	b.Success()
+
+	if got := b.Snapshot(); got.State != Closed || got.ConsecutiveFailures != 0 || got.OpenedAt != nil {
+		t.Errorf("successful probe got = %+v, want closed", got)
+	}
+}
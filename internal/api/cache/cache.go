@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"main/internal/api"
+	"main/internal/errs"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultBase is the key under which tables quoted in the provider's own base are stored.
+const defaultBase = ""
+
+type entry struct {
+	response  api.Response
+	expiresAt time.Time
+}
+
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type Cache struct {
+	provider api.CurrencyRate
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]entry
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func New(provider api.CurrencyRate, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]entry),
+	}
+}
+
+func (c *Cache) GetCurrencyRates(
+	ctx context.Context,
+	currencies []string,
+) (api.Response, error) {
+	table, err := c.table(ctx, defaultBase, func(ctx context.Context) (api.Response, error) {
+		return c.provider.GetCurrencyRates(ctx, nil)
+	})
+	if err != nil {
+		return api.Response{}, err
+	}
+
+	return table.Filter(currencies)
+}
+
+func (c *Cache) GetCurrencyRatesForBase(
+	ctx context.Context,
+	base string,
+	currencies []string,
+) (api.Response, error) {
+	baseProvider, ok := c.provider.(api.BaseCurrencyRate)
+	if !ok {
+		return api.Response{}, errs.ErrNotAllowed
+	}
+
+	table, err := c.table(ctx, base, func(ctx context.Context) (api.Response, error) {
+		return baseProvider.GetCurrencyRatesForBase(ctx, base, nil)
+	})
+	if err != nil {
+		return api.Response{}, err
+	}
+
+	return table.Filter(currencies)
+}
+
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+func (c *Cache) table(
+	ctx context.Context,
+	base string,
+	fetch func(ctx context.Context) (api.Response, error),
+) (api.Response, error) {
+	c.mu.RLock()
+	cached, ok := c.entries[base]
+	c.mu.RUnlock()
+
+	if ok && c.now().Before(cached.expiresAt) {
+		c.hits.Add(1)
+
+		return cached.response, nil
+	}
+
+	c.misses.Add(1)
+
+	resp, err := fetch(ctx)
+	if err != nil {
+		return api.Response{}, fmt.Errorf("error fetching rate table: %w", err)
+	}
+
+	c.mu.Lock()
+	c.entries[base] = entry{
+		response:  resp,
+		expiresAt: c.expiresAt(resp),
+	}
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+// expiresAt ties the entry lifetime to the moment the upstream published the table.
+// When the upstream is late with a new table, the entry is kept for a full TTL from now
+// so that we do not hammer the provider until it catches up.
+func (c *Cache) expiresAt(resp api.Response) time.Time {
+	now := c.now()
+
+	expiresAt := time.Unix(int64(resp.Timestamp), 0).Add(c.ttl)
+	if !expiresAt.After(now) {
+		return now.Add(c.ttl)
+	}
+
+	return expiresAt
+}
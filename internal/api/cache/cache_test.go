@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"main/internal/api"
+	"main/internal/errs"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MockCurrencyAPI struct {
+	calls     int
+	timestamp time.Time
+	err       error
+}
+
+func (m *MockCurrencyAPI) GetCurrencyRates(
+	_ context.Context, currencies []string,
+) (api.Response, error) {
+	m.calls++
+
+	if m.err != nil {
+		return api.Response{}, m.err
+	}
+
+	resp := api.Response{
+		Base:      "USD",
+		Timestamp: int(m.timestamp.Unix()),
+		Rates: map[string]decimal.Decimal{
+			"EUR": decimal.RequireFromString("0.869136"),
+			"GBP": decimal.RequireFromString("0.743653"),
+			"USD": decimal.RequireFromString("1"),
+		},
+	}
+
+	return resp.Filter(currencies)
+}
+
+func TestCache_GetCurrencyRates(t *testing.T) {
+	published := time.Date(2025, 6, 18, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		elapsed    []time.Duration
+		wantCalls  int
+		wantStats  Stats
+		currencies []string
+	}{
+		{
+			name:       "second request within ttl is served from cache",
+			ttl:        time.Hour,
+			elapsed:    []time.Duration{time.Minute, 2 * time.Minute},
+			wantCalls:  1,
+			wantStats:  Stats{Hits: 1, Misses: 1},
+			currencies: []string{"EUR", "GBP"},
+		},
+		{
+			name:       "ttl counted from upstream timestamp expires the entry",
+			ttl:        time.Hour,
+			elapsed:    []time.Duration{time.Minute, 61 * time.Minute},
+			wantCalls:  2,
+			wantStats:  Stats{Hits: 0, Misses: 2},
+			currencies: []string{"EUR", "GBP"},
+		},
+		{
+			name:       "stale upstream table is kept for ttl from fetch time",
+			ttl:        time.Hour,
+			elapsed:    []time.Duration{2 * time.Hour, 2*time.Hour + 30*time.Minute},
+			wantCalls:  1,
+			wantStats:  Stats{Hits: 1, Misses: 1},
+			currencies: []string{"USD", "GBP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &MockCurrencyAPI{timestamp: published}
+			cache := New(provider, tt.ttl)
+
+			for _, elapsed := range tt.elapsed {
+				cache.now = func() time.Time { return published.Add(elapsed) }
+
+				resp, err := cache.GetCurrencyRates(context.Background(), tt.currencies)
+				if err != nil {
+					t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+				}
+
+				if len(resp.Rates) != len(tt.currencies) {
+					t.Errorf("GetCurrencyRates() got %d rates, want %d", len(resp.Rates), len(tt.currencies))
+				}
+			}
+
+			if provider.calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", provider.calls, tt.wantCalls)
+			}
+
+			if got := cache.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() got = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestCache_GetCurrencyRatesErrors(t *testing.T) {
+	provider := &MockCurrencyAPI{timestamp: time.Now()}
+	cache := New(provider, time.Hour)
+
+	_, err := cache.GetCurrencyRates(context.Background(), []string{"EUR", "AAA"})
+	if !errors.Is(err, errs.ErrCurrencyNotFound) {
+		t.Errorf("GetCurrencyRates() got err = %v, want %v", err, errs.ErrCurrencyNotFound)
+	}
+
+	provider.err = errs.ErrAPIResponse
+	cache = New(provider, time.Hour)
+
+	_, err = cache.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+	if !errors.Is(err, errs.ErrAPIResponse) {
+		t.Errorf("GetCurrencyRates() got err = %v, want %v", err, errs.ErrAPIResponse)
+	}
+}
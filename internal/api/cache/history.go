@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"main/internal/api"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// History keeps historical rate tables forever, a closed day never changes.
+// Today's table is still being updated upstream, so it is never stored.
+type History struct {
+	provider api.HistoricalCurrencyRate
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	tables map[string]api.Response
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewHistory(provider api.HistoricalCurrencyRate) *History {
+	return &History{
+		provider: provider,
+		now:      time.Now,
+		tables:   make(map[string]api.Response),
+	}
+}
+
+func (h *History) GetHistoricalCurrencyRates(
+	ctx context.Context,
+	date time.Time,
+	currencies []string,
+) (api.Response, error) {
+	day := date.Format(dateLayout)
+
+	h.mu.RLock()
+	table, ok := h.tables[day]
+	h.mu.RUnlock()
+
+	if ok {
+		h.hits.Add(1)
+
+		return table.Filter(currencies)
+	}
+
+	h.misses.Add(1)
+
+	table, err := h.provider.GetHistoricalCurrencyRates(ctx, date, nil)
+	if err != nil {
+		return api.Response{}, fmt.Errorf("error fetching rate table for %s: %w", day, err)
+	}
+
+	if day < h.now().UTC().Format(dateLayout) {
+		h.mu.Lock()
+		h.tables[day] = table
+		h.mu.Unlock()
+	}
+
+	return table.Filter(currencies)
+}
+
+func (h *History) Stats() Stats {
+	return Stats{
+		Hits:   h.hits.Load(),
+		Misses: h.misses.Load(),
+	}
+}
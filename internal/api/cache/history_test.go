@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"main/internal/api"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MockHistoricalAPI struct {
+	calls int
+}
+
+func (m *MockHistoricalAPI) GetHistoricalCurrencyRates(
+	_ context.Context, date time.Time, currencies []string,
+) (api.Response, error) {
+	m.calls++
+
+	resp := api.Response{
+		Base:      "USD",
+		Timestamp: int(date.Unix()),
+		Rates:     map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.9"), "GBP": decimal.RequireFromString("0.8"), "USD": decimal.RequireFromString("1")},
+	}
+
+	return resp.Filter(currencies)
+}
+
+func TestHistory_GetHistoricalCurrencyRates(t *testing.T) {
+	today := time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		dates     []time.Time
+		wantCalls int
+		wantStats Stats
+	}{
+		{
+			name:      "past day is fetched once",
+			dates:     []time.Time{today.AddDate(0, 0, -1), today.AddDate(0, 0, -1)},
+			wantCalls: 1,
+			wantStats: Stats{Hits: 1, Misses: 1},
+		},
+		{
+			name:      "different days are fetched separately",
+			dates:     []time.Time{today.AddDate(0, 0, -2), today.AddDate(0, 0, -1)},
+			wantCalls: 2,
+			wantStats: Stats{Hits: 0, Misses: 2},
+		},
+		{
+			name:      "today is never cached",
+			dates:     []time.Time{today, today},
+			wantCalls: 2,
+			wantStats: Stats{Hits: 0, Misses: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &MockHistoricalAPI{}
+			history := NewHistory(provider)
+			history.now = func() time.Time { return today.Add(10 * time.Hour) }
+
+			for _, date := range tt.dates {
+				resp, err := history.GetHistoricalCurrencyRates(context.Background(), date, []string{"EUR", "GBP"})
+				if err != nil {
+					t.Fatalf("GetHistoricalCurrencyRates() unexpected error: %v", err)
+				}
+
+				if len(resp.Rates) != 2 {
+					t.Errorf("GetHistoricalCurrencyRates() got %d rates, want 2", len(resp.Rates))
+				}
+			}
+
+			if provider.calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", provider.calls, tt.wantCalls)
+			}
+
+			if got := history.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() got = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
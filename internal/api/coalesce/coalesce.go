@@ -0,0 +1,100 @@
+package coalesce
+
+import (
+	"context"
+	"fmt"
+	"main/internal/api"
+	"main/internal/errs"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Coalesce lets concurrent identical requests share a single upstream fetch.
+// The shared fetch is detached from the callers' contexts, so a client hanging up
+// only stops its own wait; the fetch is bounded by timeout instead.
+type Coalesce struct {
+	provider api.CurrencyRate
+	timeout  time.Duration
+	group    singleflight.Group
+}
+
+// New bounds the shared fetches with defaultTimeout when timeout is not positive.
+func New(provider api.CurrencyRate, timeout time.Duration) *Coalesce {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Coalesce{
+		provider: provider,
+		timeout:  timeout,
+	}
+}
+
+func (c *Coalesce) GetCurrencyRates(
+	ctx context.Context,
+	currencies []string,
+) (api.Response, error) {
+	return c.do(ctx, key("", currencies), func(ctx context.Context) (api.Response, error) {
+		return c.provider.GetCurrencyRates(ctx, currencies)
+	})
+}
+
+func (c *Coalesce) GetCurrencyRatesForBase(
+	ctx context.Context,
+	base string,
+	currencies []string,
+) (api.Response, error) {
+	baseProvider, ok := c.provider.(api.BaseCurrencyRate)
+	if !ok {
+		return api.Response{}, errs.ErrNotAllowed
+	}
+
+	return c.do(ctx, key(base, currencies), func(ctx context.Context) (api.Response, error) {
+		return baseProvider.GetCurrencyRatesForBase(ctx, base, currencies)
+	})
+}
+
+func (c *Coalesce) do(
+	ctx context.Context,
+	key string,
+	fetch func(ctx context.Context) (api.Response, error),
+) (api.Response, error) {
+	ch := c.group.DoChan(key, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+		defer cancel()
+
+		return fetch(fetchCtx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return api.Response{}, fmt.Errorf("error waiting for currency rates: %w", ctx.Err())
+	case result := <-ch:
+		if result.Err != nil {
+			return api.Response{}, result.Err
+		}
+
+		resp, ok := result.Val.(api.Response)
+		if !ok {
+			return api.Response{}, fmt.Errorf("error unexpected shared result %T", result.Val)
+		}
+
+		// Every waiter gets its own map, so nobody can change the rates under the others.
+		resp.Rates = maps.Clone(resp.Rates)
+
+		return resp, nil
+	}
+}
+
+func key(base string, currencies []string) string {
+	sorted := slices.Clone(currencies)
+	slices.Sort(sorted)
+
+	return base + ":" + strings.Join(sorted, ",")
+}
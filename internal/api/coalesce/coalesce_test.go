@@ -0,0 +1,160 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"main/internal/api/openexchange"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const latestJSON = `{"base":"USD","timestamp":1750240800,"rates":{"EUR":0.869136,"GBP":0.743653,"USD":1}}`
+
+type countingServer struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *countingServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.calls.Add(1)
+	<-s.release
+
+	_, _ = w.Write([]byte(latestJSON))
+}
+
+func newCoalesce(t *testing.T) (*Coalesce, *countingServer) {
+	t.Helper()
+
+	server := &countingServer{release: make(chan struct{})}
+
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	client, err := openexchange.New(srv.URL, "app-id")
+	if err != nil {
+		t.Fatalf("openexchange.New() unexpected error: %v", err)
+	}
+
+	return New(client, time.Second), server
+}
+
+func waitForCall(t *testing.T, server *countingServer) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for server.calls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("upstream was never called")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalesce_ConcurrentRequestsShareFetch(t *testing.T) {
+	coalesce, server := newCoalesce(t)
+
+	const callers = 50
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, callers)
+
+	for i := range callers {
+		currencies := []string{"EUR", "GBP"}
+		if i%2 == 1 {
+			currencies = []string{"GBP", "EUR"}
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := coalesce.GetCurrencyRates(context.Background(), currencies)
+			if err == nil && len(resp.Rates) != 2 {
+				err = errors.New("unexpected rates")
+			}
+
+			errs <- err
+		}()
+	}
+
+	waitForCall(t, server)
+	time.Sleep(50 * time.Millisecond)
+	close(server.release)
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetCurrencyRates() unexpected error: %v", err)
+		}
+	}
+
+	if got := server.calls.Load(); got != 1 {
+		t.Errorf("upstream called %d times, want 1", got)
+	}
+}
+
+func TestCoalesce_CancelledCallerDoesNotCancelSharedFetch(t *testing.T) {
+	coalesce, server := newCoalesce(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancelled := make(chan error, 1)
+
+	go func() {
+		_, err := coalesce.GetCurrencyRates(ctx, []string{"EUR", "GBP"})
+		cancelled <- err
+	}()
+
+	waitForCall(t, server)
+
+	waiting := make(chan error, 1)
+
+	go func() {
+		_, err := coalesce.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+		waiting <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller got err = %v, want %v", err, context.Canceled)
+	}
+
+	close(server.release)
+
+	if err := <-waiting; err != nil {
+		t.Errorf("remaining caller got unexpected error: %v", err)
+	}
+
+	if got := server.calls.Load(); got != 1 {
+		t.Errorf("upstream called %d times, want 1", got)
+	}
+}
+
+func TestNew_DefaultTimeout(t *testing.T) {
+	server := &countingServer{release: make(chan struct{})}
+	close(server.release)
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	client, err := openexchange.New(srv.URL, "app-id")
+	if err != nil {
+		t.Fatalf("openexchange.New() unexpected error: %v", err)
+	}
+
+	// A zero timeout would make every shared fetch fail at once.
+	if _, err := New(client, 0).GetCurrencyRates(context.Background(), []string{"EUR"}); err != nil {
+		t.Errorf("GetCurrencyRates() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package ecb
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"main/internal/api"
+	"main/internal/errs"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	apiSourceFile = "eurofxref-daily.xml"
+	quoteCurrency = "EUR"
+	defaultBase   = "USD"
+	dateLayout    = "2006-01-02"
+
+	defaultRequestTimeout = 10 * time.Second
+)
+
+type envelope struct {
+	Cube struct {
+		Days []day `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+type day struct {
+	Time  string `xml:"time,attr"`
+	Rates []rate `xml:"Cube"`
+}
+
+type rate struct {
+	Currency string `xml:"currency,attr"`
+	Rate     string `xml:"rate,attr"`
+}
+
+type ECB struct {
+	URL    *url.URL
+	base   string
+	client *http.Client
+}
+
+// New returns a client quoting rates in base, USD when base is empty.
+func New(apiURL, base string) (ECB, error) {
+	reqURL, err := url.Parse(apiURL)
+	if err != nil {
+		return ECB{}, fmt.Errorf("error parsing api url %s: %w", apiURL, err)
+	}
+
+	reqURL.Path = path.Join(reqURL.Path, apiSourceFile)
+
+	if base == "" {
+		base = defaultBase
+	}
+
+	return ECB{
+		URL:    reqURL,
+		base:   base,
+		client: &http.Client{Timeout: defaultRequestTimeout},
+	}, nil
+}
+
+func (e ECB) GetCurrencyRates(
+	ctx context.Context,
+	currencies []string,
+) (api.Response, error) {
+	return e.GetCurrencyRatesForBase(ctx, e.base, currencies)
+}
+
+// GetCurrencyRatesForBase rebases the EUR quoted reference rates locally, any published currency can be the base.
+func (e ECB) GetCurrencyRatesForBase(
+	ctx context.Context,
+	base string,
+	currencies []string,
+) (api.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.URL.String(), nil)
+	if err != nil {
+		return api.Response{}, fmt.Errorf("error creating request %s: %w", e.URL.String(), err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return api.Response{}, errs.ErrAPIResponse
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return api.Response{}, fmt.Errorf("%w: status %d", errs.ErrAPIResponse, resp.StatusCode)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return api.Response{}, fmt.Errorf("%w: error reading response body: %w", errs.ErrAPIResponse, err)
+	}
+
+	var result envelope
+
+	err = xml.Unmarshal(bodyBytes, &result)
+	if err != nil {
+		return api.Response{}, fmt.Errorf("error unmarshaling response body %s: %w", bodyBytes, err)
+	}
+
+	if len(result.Cube.Days) == 0 {
+		return api.Response{}, fmt.Errorf("%w: no reference rates in response", errs.ErrAPIResponse)
+	}
+
+	table, err := rebase(result.Cube.Days[0], base)
+	if err != nil {
+		return api.Response{}, err
+	}
+
+	return table.Filter(currencies)
+}
+
+// rebase converts EUR quoted reference rates into a table quoted in base,
+// the same shape the other providers return.
+func rebase(reference day, base string) (api.Response, error) {
+	date, err := time.Parse(dateLayout, reference.Time)
+	if err != nil {
+		return api.Response{}, fmt.Errorf("error parsing reference date %s: %w", reference.Time, err)
+	}
+
+	eurRates := make(map[string]decimal.Decimal, len(reference.Rates)+1)
+	eurRates[quoteCurrency] = decimal.NewFromInt(1)
+
+	for _, r := range reference.Rates {
+		value, err := decimal.NewFromString(r.Rate)
+		if err != nil {
+			return api.Response{}, fmt.Errorf("error parsing %s rate %s: %w", r.Currency, r.Rate, err)
+		}
+
+		eurRates[r.Currency] = value
+	}
+
+	baseRate, ok := eurRates[base]
+	if !ok || baseRate.IsZero() {
+		return api.Response{}, fmt.Errorf("%w: %w: no %s reference rate", errs.ErrInvalidBase, errs.ErrAPIResponse, base)
+	}
+
+	rates := make(map[string]decimal.Decimal, len(eurRates))
+
+	for currency, value := range eurRates {
+		rates[currency] = value.Div(baseRate)
+	}
+
+	return api.Response{
+		Rates:     rates,
+		Base:      base,
+		Timestamp: int(date.Unix()),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package ecb
+
+import (
+	"context"
+	"errors"
+	"main/internal/errs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestECB_GetCurrencyRates(t *testing.T) {
+	srv := newFixtureServer(t)
+
+	truncated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		_, _ = w.Write([]byte("<gesmes:Envelope>"))
+	}))
+	t.Cleanup(truncated.Close)
+
+	tests := []struct {
+		name       string
+		apiURL     string
+		currencies []string
+		wantRates  map[string]decimal.Decimal
+		wantErr    error
+	}{
+		{
+			name:       "rebase EUR quoted rates to USD",
+			apiURL:     srv.URL,
+			currencies: []string{"EUR", "USD", "PLN"},
+			wantRates: map[string]decimal.Decimal{
+				"EUR": decimal.RequireFromString("0.8695652173913043"),
+				"USD": decimal.RequireFromString("1"),
+				"PLN": decimal.RequireFromString("3.7191304347826087"),
+			},
+		},
+		{
+			name:       "unknown currency",
+			apiURL:     srv.URL,
+			currencies: []string{"EUR", "BTC"},
+			wantErr:    errs.ErrCurrencyNotFound,
+		},
+		{
+			name:       "missing feed",
+			apiURL:     srv.URL + "/missing",
+			currencies: []string{"EUR", "USD"},
+			wantErr:    errs.ErrAPIResponse,
+		},
+		{
+			name:       "truncated body",
+			apiURL:     truncated.URL,
+			currencies: []string{"EUR", "USD"},
+			wantErr:    errs.ErrAPIResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(tt.apiURL, "")
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			resp, err := client.GetCurrencyRates(context.Background(), tt.currencies)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetCurrencyRates() got err = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+			}
+
+			if resp.Base != "USD" || resp.Timestamp != 1750204800 {
+				t.Errorf("GetCurrencyRates() got base %s timestamp %d", resp.Base, resp.Timestamp)
+			}
+
+			if len(resp.Rates) != len(tt.wantRates) {
+				t.Fatalf("GetCurrencyRates() got = %v, want %v", resp.Rates, tt.wantRates)
+			}
+
+			for currency, want := range tt.wantRates {
+				if !resp.Rates[currency].Equal(want) {
+					t.Errorf("GetCurrencyRates() %s got = %v, want %v", currency, resp.Rates[currency], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/api"
+	"main/internal/errs"
+	"time"
+)
+
+type Provider struct {
+	Name    string
+	API     api.CurrencyRate
+	Timeout time.Duration
+}
+
+type Failover struct {
+	providers []Provider
+}
+
+func New(providers ...Provider) (*Failover, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("error failover chain needs at least one provider")
+	}
+
+	return &Failover{
+		providers: providers,
+	}, nil
+}
+
+func (f *Failover) GetCurrencyRates(
+	ctx context.Context,
+	currencies []string,
+) (api.Response, error) {
+	return f.try(ctx, func(ctx context.Context, provider api.CurrencyRate) (api.Response, error) {
+		return provider.GetCurrencyRates(ctx, currencies)
+	})
+}
+
+// GetCurrencyRatesForBase asks only the providers able to quote rates in base.
+func (f *Failover) GetCurrencyRatesForBase(
+	ctx context.Context,
+	base string,
+	currencies []string,
+) (api.Response, error) {
+	return f.try(ctx, func(ctx context.Context, provider api.CurrencyRate) (api.Response, error) {
+		baseProvider, ok := provider.(api.BaseCurrencyRate)
+		if !ok {
+			return api.Response{}, errs.ErrNotAllowed
+		}
+
+		return baseProvider.GetCurrencyRatesForBase(ctx, base, currencies)
+	})
+}
+
+// GetHistoricalCurrencyRates asks only the providers that keep a rate history.
+func (f *Failover) GetHistoricalCurrencyRates(
+	ctx context.Context,
+	date time.Time,
+	currencies []string,
+) (api.Response, error) {
+	return f.try(ctx, func(ctx context.Context, provider api.CurrencyRate) (api.Response, error) {
+		historical, ok := provider.(api.HistoricalCurrencyRate)
+		if !ok {
+			return api.Response{}, errs.ErrHistoryNotSupported
+		}
+
+		return historical.GetHistoricalCurrencyRates(ctx, date, currencies)
+	})
+}
+
+func (f *Failover) try(
+	ctx context.Context,
+	call func(ctx context.Context, provider api.CurrencyRate) (api.Response, error),
+) (api.Response, error) {
+	failures := make([]error, 0, len(f.providers))
+
+	for _, provider := range f.providers {
+		resp, err := f.fetch(ctx, provider, call)
+		if err == nil {
+			resp.Provider = provider.Name
+
+			return resp, nil
+		}
+
+		if ctx.Err() != nil || !retriable(err) {
+			return api.Response{}, fmt.Errorf("provider %s: %w", provider.Name, err)
+		}
+
+		if !errors.Is(err, errs.ErrHistoryNotSupported) && !errors.Is(err, errs.ErrNotAllowed) {
+			slog.Warn("currency rate provider failed, trying next one",
+				slog.String("provider", provider.Name),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		failures = append(failures, fmt.Errorf("provider %s: %w", provider.Name, err))
+	}
+
+	return api.Response{}, fmt.Errorf("all providers failed: %w", errors.Join(failures...))
+}
+
+func (f *Failover) fetch(
+	ctx context.Context,
+	provider Provider,
+	call func(ctx context.Context, provider api.CurrencyRate) (api.Response, error),
+) (api.Response, error) {
+	if provider.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, provider.Timeout)
+		defer cancel()
+	}
+
+	return call(ctx, provider.API)
+}
+
+func retriable(err error) bool {
+	return errors.Is(err, errs.ErrAPIResponse) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, errs.ErrCircuitOpen) ||
+		errors.Is(err, errs.ErrNotAllowed) ||
+		errors.Is(err, errs.ErrHistoryNotSupported)
+}
@@ -0,0 +1,134 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/api/openexchange"
+	"main/internal/errs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const latestJSON = `{"base":"USD","timestamp":1750240800,"rates":{"EUR":0.869136,"GBP":0.743653,"USD":1}}`
+
+func newServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func healthy(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte(latestJSON))
+}
+
+func failing(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func slow(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-r.Context().Done():
+	case <-time.After(time.Second):
+		healthy(w, r)
+	}
+}
+
+func TestFailover_GetCurrencyRates(t *testing.T) {
+	tests := []struct {
+		name         string
+		handlers     []http.HandlerFunc
+		currencies   []string
+		wantProvider string
+		wantErr      error
+	}{
+		{
+			name:         "first provider serves the answer",
+			handlers:     []http.HandlerFunc{healthy, failing},
+			currencies:   []string{"EUR", "GBP"},
+			wantProvider: "provider-0",
+		},
+		{
+			name:         "failing provider is skipped",
+			handlers:     []http.HandlerFunc{failing, healthy},
+			currencies:   []string{"EUR", "GBP"},
+			wantProvider: "provider-1",
+		},
+		{
+			name:         "timed out provider is skipped",
+			handlers:     []http.HandlerFunc{slow, failing, healthy},
+			currencies:   []string{"EUR", "GBP"},
+			wantProvider: "provider-2",
+		},
+		{
+			name:       "all providers fail",
+			handlers:   []http.HandlerFunc{failing, slow},
+			currencies: []string{"EUR", "GBP"},
+			wantErr:    errs.ErrAPIResponse,
+		},
+		{
+			name:       "unknown currency is not retried",
+			handlers:   []http.HandlerFunc{healthy, failing},
+			currencies: []string{"EUR", "AAA"},
+			wantErr:    errs.ErrCurrencyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers := make([]Provider, 0, len(tt.handlers))
+
+			for i, handler := range tt.handlers {
+				srv := newServer(t, handler)
+
+				client, err := openexchange.New(srv.URL, "app-id")
+				if err != nil {
+					t.Fatalf("openexchange.New() unexpected error: %v", err)
+				}
+
+				providers = append(providers, Provider{
+					Name:    fmt.Sprintf("provider-%d", i),
+					API:     client,
+					Timeout: 100 * time.Millisecond,
+				})
+			}
+
+			chain, err := New(providers...)
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			resp, err := chain.GetCurrencyRates(context.Background(), tt.currencies)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetCurrencyRates() got err = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+			}
+
+			if resp.Provider != tt.wantProvider {
+				t.Errorf("GetCurrencyRates() served by %q, want %q", resp.Provider, tt.wantProvider)
+			}
+
+			if len(resp.Rates) != len(tt.currencies) {
+				t.Errorf("GetCurrencyRates() got %d rates, want %d", len(resp.Rates), len(tt.currencies))
+			}
+		})
+	}
+}
+
+func TestNew_EmptyChain(t *testing.T) {
+	if _, err := New(); err == nil {
+		t.Error("New() expected error for empty chain")
+	}
+}
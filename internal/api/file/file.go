@@ -0,0 +1,200 @@
+package file
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"main/internal/api"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultBase = "USD"
+
+	formatJSON = ".json"
+	formatCSV  = ".csv"
+)
+
+// File serves rates from a local snapshot, either in the openexchangerates latest.json
+// format or a CSV file with currency,rate rows quoted in base.
+type File struct {
+	path string
+	base string
+
+	table   atomic.Pointer[api.Response]
+	modTime time.Time
+
+	interval time.Duration
+}
+
+// New loads the snapshot at path, base is used for CSV files only, USD when empty.
+// A positive interval makes Run reload the file whenever it changes.
+func New(path, base string, interval time.Duration) (*File, error) {
+	if base == "" {
+		base = defaultBase
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case formatJSON, formatCSV:
+	default:
+		return nil, fmt.Errorf("unsupported rates file %s, expected .json or .csv", path)
+	}
+
+	f := &File{
+		path:     path,
+		base:     base,
+		interval: interval,
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rates file: %w", err)
+	}
+
+	if err := f.load(info.ModTime()); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *File) GetCurrencyRates(
+	_ context.Context,
+	currencies []string,
+) (api.Response, error) {
+	return f.table.Load().Filter(currencies)
+}
+
+// Run reloads the snapshot every interval when the file was modified, until ctx is cancelled.
+// A file that fails to load keeps the previous table in service.
+func (f *File) Run(ctx context.Context) {
+	if f.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Rates file watcher stopped", slog.String("path", f.path))
+
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(f.path)
+		if err != nil {
+			slog.Warn("Rates file unavailable, serving previous table",
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if info.ModTime().Equal(f.modTime) {
+			continue
+		}
+
+		if err := f.load(info.ModTime()); err != nil {
+			slog.Warn("Rates file reload failed, serving previous table",
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		slog.Info("Rates file reloaded", slog.String("path", f.path))
+	}
+}
+
+func (f *File) load(modTime time.Time) error {
+	src, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("error opening rates file: %w", err)
+	}
+
+	defer src.Close()
+
+	var table api.Response
+
+	if strings.ToLower(filepath.Ext(f.path)) == formatCSV {
+		table, err = parseCSV(src, f.base, modTime)
+	} else {
+		table, err = parseJSON(src)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error parsing rates file %s: %w", f.path, err)
+	}
+
+	f.table.Store(&table)
+	f.modTime = modTime
+
+	return nil
+}
+
+func parseJSON(src io.Reader) (api.Response, error) {
+	var table api.Response
+
+	if err := json.NewDecoder(src).Decode(&table); err != nil {
+		return api.Response{}, err
+	}
+
+	if table.Base == "" || len(table.Rates) == 0 {
+		return api.Response{}, errors.New("base and rates are required")
+	}
+
+	if _, ok := table.Rates[table.Base]; !ok {
+		table.Rates[table.Base] = decimal.NewFromInt(1)
+	}
+
+	return table, nil
+}
+
+// parseCSV reads currency,rate rows, a header row is optional.
+func parseCSV(src io.Reader, base string, modTime time.Time) (api.Response, error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return api.Response{}, err
+	}
+
+	if len(records) > 0 && strings.EqualFold(records[0][0], "currency") {
+		records = records[1:]
+	}
+
+	if len(records) == 0 {
+		return api.Response{}, errors.New("no rates")
+	}
+
+	rates := make(map[string]decimal.Decimal, len(records)+1)
+	rates[base] = decimal.NewFromInt(1)
+
+	for i, record := range records {
+		rate, err := decimal.NewFromString(record[1])
+		if err != nil {
+			return api.Response{}, fmt.Errorf("row %d: invalid rate %q: %w", i+1, record[1], err)
+		}
+
+		rates[strings.ToUpper(record[0])] = rate
+	}
+
+	return api.Response{
+		Rates:     rates,
+		Base:      base,
+		Timestamp: int(modTime.Unix()),
+	}, nil
+}
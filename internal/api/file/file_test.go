@@ -0,0 +1,169 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"main/internal/errs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFile_GetCurrencyRates(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		base       string
+		currencies []string
+		wantRates  map[string]string
+		wantErr    error
+	}{
+		{
+			name:       "latest.json snapshot",
+			path:       "testdata/latest.json",
+			currencies: []string{"USD", "EUR", "PLN"},
+			wantRates:  map[string]string{"USD": "1", "EUR": "0.869136", "PLN": "3.7191304347826087"},
+		},
+		{
+			name:       "csv snapshot in configured base",
+			path:       "testdata/rates.csv",
+			base:       "USD",
+			currencies: []string{"USD", "GBP", "PLN"},
+			wantRates:  map[string]string{"USD": "1", "GBP": "0.743653", "PLN": "3.7191304347826087"},
+		},
+		{
+			name:       "unknown currency",
+			path:       "testdata/latest.json",
+			currencies: []string{"USD", "BTC"},
+			wantErr:    errs.ErrCurrencyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New(tt.path, tt.base, 0)
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			resp, err := provider.GetCurrencyRates(context.Background(), tt.currencies)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetCurrencyRates() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+			}
+
+			if len(resp.Rates) != len(tt.wantRates) {
+				t.Fatalf("GetCurrencyRates() got = %v, want %v", resp.Rates, tt.wantRates)
+			}
+
+			for currency, want := range tt.wantRates {
+				if got := resp.Rates[currency].String(); got != want {
+					t.Errorf("GetCurrencyRates() %s got = %s, want %s", currency, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNew_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "unsupported extension", file: "rates.txt", content: "EUR,0.9"},
+		{name: "malformed json", file: "latest.json", content: `{"base":"USD","rates":`},
+		{name: "json without rates", file: "latest.json", content: `{"base":"USD","rates":{}}`},
+		{name: "csv rate not a number", file: "rates.csv", content: "EUR,abc"},
+		{name: "csv without rows", file: "rates.csv", content: "currency,rate\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			if _, err := New(path, "", 0); err == nil {
+				t.Errorf("New() expected error for %s", tt.content)
+			}
+		})
+	}
+
+	if _, err := New(filepath.Join(dir, "missing.json"), "", 0); err == nil {
+		t.Errorf("New() expected error for a missing file")
+	}
+}
+
+func TestFile_Run(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.csv")
+
+	write := func(content string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	published := time.Date(2025, 6, 18, 10, 0, 0, 0, time.UTC)
+	write("EUR,0.9\n", published)
+
+	provider, err := New(path, "USD", 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		provider.Run(ctx)
+		close(done)
+	}()
+
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	rateEUR := func() string {
+		resp, err := provider.GetCurrencyRates(context.Background(), []string{"EUR"})
+		if err != nil {
+			t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+		}
+
+		return resp.Rates["EUR"].String()
+	}
+
+	write("EUR,abc\n", published.Add(time.Minute))
+	time.Sleep(30 * time.Millisecond)
+
+	if got := rateEUR(); got != "0.9" {
+		t.Errorf("rate after broken reload got = %s, want 0.9", got)
+	}
+
+	write("EUR,0.95\n", published.Add(2*time.Minute))
+
+	deadline := time.Now().Add(time.Second)
+	for rateEUR() != "0.95" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := rateEUR(); got != "0.95" {
+		t.Errorf("rate after reload got = %s, want 0.95", got)
+	}
+}
@@ -3,25 +3,82 @@ package openexchange
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"main/internal/api"
+	"main/internal/api/breaker"
 	"main/internal/errs"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 const (
 	apiSourceFile = "latest.json"
-	baseCurrency  = "USD"
+	historicalDir = "historical"
+	dateLayout    = "2006-01-02"
+	defaultBase   = "USD"
+
+	defaultRequestTimeout = 10 * time.Second
 )
 
+type Option func(*OpenExchange)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *OpenExchange) {
+		o.client = client
+	}
+}
+
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *OpenExchange) {
+		o.retry = policy
+	}
+}
+
+func WithBase(base string) Option {
+	return func(o *OpenExchange) {
+		o.base = base
+	}
+}
+
+// WithSymbols makes the client ask the upstream only for the needed currencies.
+func WithSymbols() Option {
+	return func(o *OpenExchange) {
+		o.symbols = true
+	}
+}
+
+func WithCircuitBreaker(b *breaker.Breaker) Option {
+	return func(o *OpenExchange) {
+		o.breaker = b
+	}
+}
+
 type OpenExchange struct {
-	URL *url.URL
+	URL     *url.URL
+	base    string
+	symbols bool
+	client  *http.Client
+	retry   RetryPolicy
+	breaker *breaker.Breaker
+	// baseNotAllowed and symbolsNotAllowed remember what the plan does not allow,
+	// so we do not waste quota asking again.
+	baseNotAllowed    *atomic.Bool
+	symbolsNotAllowed *atomic.Bool
+	// requests counts every answered HTTP request but usage.json, which is not billed.
+	requests *atomic.Uint64
 }
 
-func New(apiURL, apiAppID string) (OpenExchange, error) {
+// New accepts an empty apiAppID for the clients that never reach the real upstream,
+// e.g. replaying fixtures, the app_id parameter is left out then.
+func New(apiURL, apiAppID string, opts ...Option) (OpenExchange, error) {
 	reqURL, err := url.Parse(apiURL)
 	if err != nil {
 		return OpenExchange{}, fmt.Errorf("error parsing api url %s: %w", apiURL, err)
@@ -29,36 +86,137 @@ func New(apiURL, apiAppID string) (OpenExchange, error) {
 
 	reqURL.Path = path.Join(reqURL.Path, apiSourceFile)
 
+	openExchange := OpenExchange{
+		URL:               reqURL,
+		base:              defaultBase,
+		client:            &http.Client{Timeout: defaultRequestTimeout},
+		baseNotAllowed:    &atomic.Bool{},
+		symbolsNotAllowed: &atomic.Bool{},
+		requests:          &atomic.Uint64{},
+	}
+
+	for _, opt := range opts {
+		opt(&openExchange)
+	}
+
 	query := url.Values{}
-	query.Set("app_id", apiAppID)
-	query.Set("base", baseCurrency)
+	if apiAppID != "" {
+		query.Set("app_id", apiAppID)
+	}
+
+	query.Set("base", openExchange.base)
 
 	reqURL.RawQuery = query.Encode()
 
-	return OpenExchange{
-		URL: reqURL,
-	}, nil
+	return openExchange, nil
+}
+
+func (o OpenExchange) Requests() uint64 {
+	return o.requests.Load()
+}
+
+func (o OpenExchange) CircuitBreaker() (breaker.Snapshot, bool) {
+	if o.breaker == nil {
+		return breaker.Snapshot{}, false
+	}
+
+	return o.breaker.Snapshot(), true
 }
 
 func (o OpenExchange) GetCurrencyRates(
 	ctx context.Context,
 	currencies []string,
 ) (api.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.URL.String(), nil)
-	if err != nil {
-		return api.Response{}, fmt.Errorf("error creating request %s: %w", o.URL.String(), err)
+	return o.fetch(ctx, o.URL, currencies)
+}
+
+// GetCurrencyRatesForBase asks the upstream for rates quoted in base,
+// which not every openexchangerates plan allows.
+func (o OpenExchange) GetCurrencyRatesForBase(
+	ctx context.Context,
+	base string,
+	currencies []string,
+) (api.Response, error) {
+	if base == o.base {
+		return o.GetCurrencyRates(ctx, currencies)
+	}
+
+	if o.baseNotAllowed.Load() {
+		return api.Response{}, errs.ErrNotAllowed
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	reqURL := *o.URL
+
+	query := reqURL.Query()
+	query.Set("base", base)
+	reqURL.RawQuery = query.Encode()
+
+	resp, err := o.fetch(ctx, &reqURL, currencies)
+	if errors.Is(err, errs.ErrNotAllowed) {
+		o.baseNotAllowed.Store(true)
+	}
+
+	return resp, err
+}
+
+func (o OpenExchange) GetHistoricalCurrencyRates(
+	ctx context.Context,
+	date time.Time,
+	currencies []string,
+) (api.Response, error) {
+	reqURL := *o.URL
+	reqURL.Path = path.Join(path.Dir(o.URL.Path), historicalDir, date.Format(dateLayout)+".json")
+
+	return o.fetch(ctx, &reqURL, currencies)
+}
+
+// fetch asks the upstream only for the needed currencies when the provider supports
+// the symbols parameter, otherwise the full table is downloaded and filtered here.
+func (o OpenExchange) fetch(
+	ctx context.Context,
+	reqURL *url.URL,
+	currencies []string,
+) (api.Response, error) {
+	if !o.symbols || len(currencies) == 0 || o.symbolsNotAllowed.Load() {
+		result, err := o.fetchTable(ctx, reqURL)
+		if err != nil {
+			return api.Response{}, err
+		}
+
+		return result.Filter(currencies)
+	}
+
+	symbolsURL := *reqURL
+
+	query := symbolsURL.Query()
+	query.Set("symbols", strings.Join(currencies, ","))
+	symbolsURL.RawQuery = query.Encode()
+
+	result, err := o.fetchTable(ctx, &symbolsURL)
+	if !errors.Is(err, errs.ErrNotAllowed) {
+		if err != nil {
+			return api.Response{}, err
+		}
+
+		return result.Filter(currencies)
+	}
+
+	// Either symbols or the base is not allowed by the plan, the full table tells which one.
+	result, err = o.fetchTable(ctx, reqURL)
 	if err != nil {
-		return api.Response{}, errs.ErrAPIResponse
+		return api.Response{}, err
 	}
 
-	defer resp.Body.Close()
+	o.symbolsNotAllowed.Store(true)
+	slog.Warn("openexchange plan does not allow the symbols parameter, downloading full tables")
+
+	return result.Filter(currencies)
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+func (o OpenExchange) fetchTable(ctx context.Context, reqURL *url.URL) (api.Response, error) {
+	bodyBytes, err := o.get(ctx, reqURL)
 	if err != nil {
-		return api.Response{}, fmt.Errorf("error reading response body %s: %w", resp.Body, err)
+		return api.Response{}, err
 	}
 
 	var result api.Response
@@ -68,18 +226,13 @@ func (o OpenExchange) GetCurrencyRates(
 		return api.Response{}, fmt.Errorf("error unmarshaling response body %s: %w", bodyBytes, err)
 	}
 
-	neededCurrencies := make(map[string]float64, len(currencies))
-
-	for _, currency := range currencies {
-		val, ok := result.Rates[currency]
-		if !ok {
-			return api.Response{}, errs.ErrCurrencyNotFound
-		}
-
-		neededCurrencies[currency] = val
+	if result.Rates == nil {
+		return api.Response{}, fmt.Errorf("%w: no rates in response", errs.ErrAPIResponse)
 	}
 
-	result.Rates = neededCurrencies
+	if _, ok := result.Rates[result.Base]; !ok && result.Base != "" {
+		result.Rates[result.Base] = decimal.NewFromInt(1)
+	}
 
 	return result, nil
 }
@@ -0,0 +1,605 @@
+package openexchange
+
+import (
+	"context"
+	"errors"
+	"main/internal/api/breaker"
+	"main/internal/errs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const latestJSON = `{"base":"USD","timestamp":1750240800,"rates":{"EUR":0.869136,"GBP":0.743653,"USD":1}}`
+
+type scriptedServer struct {
+	statuses []int
+	header   http.Header
+	calls    atomic.Int32
+}
+
+func (s *scriptedServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	call := int(s.calls.Add(1)) - 1
+
+	status := http.StatusOK
+	if call < len(s.statuses) {
+		status = s.statuses[call]
+	}
+
+	if status != http.StatusOK {
+		for key, values := range s.header {
+			w.Header()[key] = values
+		}
+
+		w.WriteHeader(status)
+
+		return
+	}
+
+	_, _ = w.Write([]byte(latestJSON))
+}
+
+func TestOpenExchange_Retry(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	tests := []struct {
+		name      string
+		statuses  []int
+		header    http.Header
+		wantCalls int32
+		wantErr   error
+	}{
+		{
+			name:      "server error is retried",
+			statuses:  []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			wantCalls: 3,
+		},
+		{
+			name:      "too many requests honors short Retry-After",
+			statuses:  []int{http.StatusTooManyRequests},
+			header:    http.Header{"Retry-After": []string{"0"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "Retry-After longer than max delay is not waited for",
+			statuses:  []int{http.StatusTooManyRequests},
+			header:    http.Header{"Retry-After": []string{"120"}},
+			wantCalls: 1,
+			wantErr:   errs.ErrAPIResponse,
+		},
+		{
+			name:      "retries are exhausted",
+			statuses:  []int{500, 500, 500, 500},
+			wantCalls: 3,
+			wantErr:   errs.ErrAPIResponse,
+		},
+		{
+			name:      "client error is not retried",
+			statuses:  []int{http.StatusUnauthorized},
+			wantCalls: 1,
+			wantErr:   errs.ErrAPIResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &scriptedServer{statuses: tt.statuses, header: tt.header}
+
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			client, err := New(srv.URL, "app-id", WithRetry(policy))
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			_, err = client.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetCurrencyRates() got err = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := server.calls.Load(); got != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestOpenExchange_CircuitBreaker(t *testing.T) {
+	server := &scriptedServer{statuses: []int{500, 500}}
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	client, err := New(srv.URL, "app-id", WithCircuitBreaker(breaker.New(2, time.Hour)))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	for range 2 {
+		_, err = client.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+		if !errors.Is(err, errs.ErrAPIResponse) {
+			t.Fatalf("GetCurrencyRates() got err = %v, want %v", err, errs.ErrAPIResponse)
+		}
+	}
+
+	_, err = client.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+	if !errors.Is(err, errs.ErrCircuitOpen) {
+		t.Errorf("GetCurrencyRates() got err = %v, want %v", err, errs.ErrCircuitOpen)
+	}
+
+	if got := server.calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2", got)
+	}
+
+	snapshot, ok := client.CircuitBreaker()
+	if !ok || snapshot.State != breaker.Open || snapshot.ConsecutiveFailures != 2 {
+		t.Errorf("CircuitBreaker() got = %+v, want open after 2 failures", snapshot)
+	}
+}
+
+func TestOpenExchange_CircuitBreakerOutcomes(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		ctx          func() (context.Context, context.CancelFunc)
+		wantFailures int
+	}{
+		{
+			name: "caller deadline counts as a failure",
+			handler: func(_ http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			wantFailures: 2,
+		},
+		{
+			name:    "cancellation is not a failure",
+			handler: func(http.ResponseWriter, *http.Request) {},
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				return ctx, cancel
+			},
+			wantFailures: 1,
+		},
+		{
+			name: "unauthorized does not reset the failures",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			b := breaker.New(5, time.Hour)
+			b.Failure()
+
+			client, err := New(srv.URL, "app-id", WithCircuitBreaker(b))
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			if _, err = client.GetCurrencyRates(ctx, []string{"EUR"}); err == nil {
+				t.Fatalf("GetCurrencyRates() expected an error")
+			}
+
+			if got := b.Snapshot().ConsecutiveFailures; got != tt.wantFailures {
+				t.Errorf("ConsecutiveFailures got = %d, want %d", got, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 18, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{name: "empty", header: "", want: 0},
+		{name: "seconds", header: "3", want: 3 * time.Second},
+		{name: "http date", header: "Wed, 18 Jun 2025 10:00:05 GMT", want: 5 * time.Second},
+		{name: "date in the past", header: "Wed, 18 Jun 2025 09:00:00 GMT", want: 0},
+		{name: "garbage", header: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("parseRetryAfter() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenExchange_ErrorPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name:      "invalid app id",
+			status:    http.StatusUnauthorized,
+			body:      `{"error":true,"status":401,"message":"invalid_app_id","description":"Invalid App ID provided."}`,
+			wantErr:   errs.ErrInvalidAppID,
+			wantCalls: 1,
+		},
+		{
+			name:      "missing app id",
+			status:    http.StatusUnauthorized,
+			body:      `{"error":true,"status":401,"message":"missing_app_id","description":"No App ID provided."}`,
+			wantErr:   errs.ErrInvalidAppID,
+			wantCalls: 1,
+		},
+		{
+			name:      "quota exceeded is not retried",
+			status:    http.StatusTooManyRequests,
+			body:      `{"error":true,"status":429,"message":"access_restricted","description":"Access restricted for repeated over-use."}`,
+			wantErr:   errs.ErrQuotaExceeded,
+			wantCalls: 1,
+		},
+		{
+			name:      "feature not allowed",
+			status:    http.StatusForbidden,
+			body:      `{"error":true,"status":403,"message":"not_allowed","description":"Changing the API base currency is available for Developer, Enterprise and Unlimited plan clients."}`,
+			wantErr:   errs.ErrNotAllowed,
+			wantCalls: 1,
+		},
+		{
+			name:      "invalid base",
+			status:    http.StatusBadRequest,
+			body:      `{"error":true,"status":400,"message":"invalid_base","description":"Invalid base currency."}`,
+			wantErr:   errs.ErrInvalidBase,
+			wantCalls: 1,
+		},
+		{
+			name:      "unknown payload is still an api error",
+			status:    http.StatusNotFound,
+			body:      `{"error":true,"status":404,"message":"not_found","description":"Not found."}`,
+			wantErr:   errs.ErrAPIResponse,
+			wantCalls: 1,
+		},
+		{
+			name:      "successful status without rates",
+			status:    http.StatusOK,
+			body:      `{"disclaimer":"Usage subject to terms"}`,
+			wantErr:   errs.ErrAPIResponse,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client, err := New(srv.URL, "app-id", WithRetry(RetryPolicy{MaxRetries: 2}))
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			_, err = client.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetCurrencyRates() got err = %v, want %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, errs.ErrCurrencyNotFound) {
+				t.Errorf("GetCurrencyRates() reported %v for upstream error", errs.ErrCurrencyNotFound)
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestOpenExchange_GetUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/usage.json" || r.URL.Query().Get("app_id") != "app-id" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"status":200,"data":{"app_id":"app-id","status":"active",` +
+			`"plan":{"name":"Free","quota":"1000 requests / month","update_frequency":"3600s",` +
+			`"features":{"base":false,"symbols":false,"time-series":false}},` +
+			`"usage":{"requests":12,"requests_quota":1000,"requests_remaining":988,` +
+			`"days_elapsed":1,"days_remaining":29,"daily_average":12}}}`))
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.URL+"/api/", "app-id")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	usage, err := client.GetUsage(context.Background())
+	if err != nil {
+		t.Fatalf("GetUsage() unexpected error: %v", err)
+	}
+
+	if usage.Plan != "Free" || usage.Requests != 12 || usage.RequestsQuota != 1000 ||
+		usage.RequestsRemaining != 988 || usage.DaysElapsed != 1 || usage.DaysRemaining != 29 {
+		t.Errorf("GetUsage() got = %+v", usage)
+	}
+
+	if usage.Features["base"] || len(usage.Features) != 3 {
+		t.Errorf("GetUsage() got features = %v", usage.Features)
+	}
+}
+
+func TestOpenExchange_ExactRates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"base":"USD","timestamp":1750240800,"rates":{"VES":1234567890123.123456789,"USD":1}}`))
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.URL, "app-id")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	resp, err := client.GetCurrencyRates(context.Background(), []string{"VES"})
+	if err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if got := resp.Rates["VES"].String(); got != "1234567890123.123456789" {
+		t.Errorf("GetCurrencyRates() VES got = %s, want 1234567890123.123456789", got)
+	}
+}
+
+func TestOpenExchange_GetCurrencyNames(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/currencies.json" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"EUR":"Euro","USD":"United States Dollar"}`))
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.URL+"/api/", "app-id")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	names, err := client.GetCurrencyNames(context.Background())
+	if err != nil {
+		t.Fatalf("GetCurrencyNames() unexpected error: %v", err)
+	}
+
+	if len(names) != 2 || names["EUR"] != "Euro" || names["USD"] != "United States Dollar" {
+		t.Errorf("GetCurrencyNames() got = %v", names)
+	}
+}
+
+func TestOpenExchange_GetCurrencyRatesForBase(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		switch r.URL.Query().Get("base") {
+		case "USD":
+			_, _ = w.Write([]byte(latestJSON))
+		case "EUR":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":true,"status":403,"message":"not_allowed","description":"Changing the API base currency is available for Developer, Enterprise and Unlimited plan clients."}`))
+		default:
+			_, _ = w.Write([]byte(`{"base":"GBP","timestamp":1750240800,"rates":{"EUR":1.1687,"USD":1.3447}}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.URL, "app-id")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	resp, err := client.GetCurrencyRatesForBase(context.Background(), "GBP", []string{"EUR", "GBP"})
+	if err != nil {
+		t.Fatalf("GetCurrencyRatesForBase() unexpected error: %v", err)
+	}
+
+	if resp.Base != "GBP" || !resp.Rates["GBP"].Equal(decimal.NewFromInt(1)) ||
+		!resp.Rates["EUR"].Equal(decimal.RequireFromString("1.1687")) {
+		t.Errorf("GetCurrencyRatesForBase() got = %+v", resp)
+	}
+
+	for range 2 {
+		_, err = client.GetCurrencyRatesForBase(context.Background(), "EUR", []string{"USD"})
+		if !errors.Is(err, errs.ErrNotAllowed) {
+			t.Errorf("GetCurrencyRatesForBase() got err = %v, want %v", err, errs.ErrNotAllowed)
+		}
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2, not allowed base must not be asked again", got)
+	}
+}
+
+func TestOpenExchange_Symbols(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		allowSymbols   bool
+		requests       int
+		wantSymbols    []string
+		wantCalls      int32
+		wantRatesCount int
+	}{
+		{
+			name:           "symbols requested when supported",
+			opts:           []Option{WithSymbols()},
+			allowSymbols:   true,
+			requests:       2,
+			wantSymbols:    []string{"EUR,GBP", "EUR,GBP"},
+			wantCalls:      2,
+			wantRatesCount: 2,
+		},
+		{
+			name:           "full table without capability",
+			allowSymbols:   true,
+			requests:       1,
+			wantSymbols:    []string{""},
+			wantCalls:      1,
+			wantRatesCount: 2,
+		},
+		{
+			name:           "falls back to full table when plan does not allow symbols",
+			opts:           []Option{WithSymbols()},
+			allowSymbols:   false,
+			requests:       2,
+			wantSymbols:    []string{"EUR,GBP", "", ""},
+			wantCalls:      3,
+			wantRatesCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				calls   atomic.Int32
+				symbols = make(chan string, 10)
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+
+				requested := r.URL.Query().Get("symbols")
+				symbols <- requested
+
+				if requested != "" && !tt.allowSymbols {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"error":true,"status":403,"message":"not_allowed","description":"Not allowed."}`))
+
+					return
+				}
+
+				if requested != "" {
+					_, _ = w.Write([]byte(`{"base":"USD","timestamp":1750240800,"rates":{"EUR":0.869136,"GBP":0.743653}}`))
+
+					return
+				}
+
+				_, _ = w.Write([]byte(latestJSON))
+			}))
+			defer srv.Close()
+
+			client, err := New(srv.URL, "app-id", tt.opts...)
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			for range tt.requests {
+				resp, err := client.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+				if err != nil {
+					t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+				}
+
+				if len(resp.Rates) != tt.wantRatesCount {
+					t.Errorf("GetCurrencyRates() got %d rates, want %d", len(resp.Rates), tt.wantRatesCount)
+				}
+			}
+
+			close(symbols)
+
+			got := make([]string, 0, len(tt.wantSymbols))
+			for requested := range symbols {
+				got = append(got, requested)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantSymbols) {
+				t.Errorf("requested symbols = %q, want %q", got, tt.wantSymbols)
+			}
+
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestNew_WithoutAppID(t *testing.T) {
+	var query string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(latestJSON))
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.URL, "")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if _, err := client.GetCurrencyRates(context.Background(), []string{"EUR"}); err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if query != "base=USD" {
+		t.Errorf("request query got = %q, want no app_id", query)
+	}
+}
+
+func TestOpenExchange_Requests(t *testing.T) {
+	server := &scriptedServer{statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable}}
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	client, err := New(srv.URL, "app-id", WithRetry(policy))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if _, err := client.GetCurrencyRates(context.Background(), []string{"EUR"}); err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	// usage.json is not billed.
+	_, _ = client.GetUsage(context.Background())
+
+	if got := client.Requests(); got != 3 {
+		t.Errorf("Requests() got = %d, want 3", got)
+	}
+}
@@ -0,0 +1,31 @@
+package openexchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+const currenciesFile = "currencies.json"
+
+// GetCurrencyNames returns the full names of all currencies the upstream quotes, keyed by code.
+func (o OpenExchange) GetCurrencyNames(ctx context.Context) (map[string]string, error) {
+	reqURL := *o.URL
+	reqURL.Path = path.Join(path.Dir(o.URL.Path), currenciesFile)
+	reqURL.RawQuery = ""
+
+	bodyBytes, err := o.get(ctx, &reqURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var names map[string]string
+
+	err = json.Unmarshal(bodyBytes, &names)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling currencies body %s: %w", bodyBytes, err)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,64 @@
+package openexchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"main/internal/errs"
+)
+
+// errorResponse is the body openexchangerates sends along with a non 200 status.
+type errorResponse struct {
+	Error       bool   `json:"error"`
+	Status      int    `json:"status"`
+	Message     string `json:"message"`
+	Description string `json:"description"`
+}
+
+type APIError struct {
+	Status      int
+	Message     string
+	Description string
+	kind        error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("openexchange error %d %s: %s", e.Status, e.Message, e.Description)
+}
+
+func (e *APIError) Unwrap() []error {
+	if e.kind == nil {
+		return []error{errs.ErrAPIResponse}
+	}
+
+	return []error{e.kind, errs.ErrAPIResponse}
+}
+
+func parseErrorResponse(status int, body []byte) error {
+	var payload errorResponse
+
+	if err := json.Unmarshal(body, &payload); err != nil || !payload.Error {
+		return fmt.Errorf("%w: status %d", errs.ErrAPIResponse, status)
+	}
+
+	return &APIError{
+		Status:      payload.Status,
+		Message:     payload.Message,
+		Description: payload.Description,
+		kind:        errorKind(payload.Message),
+	}
+}
+
+func errorKind(message string) error {
+	switch message {
+	case "invalid_app_id", "missing_app_id":
+		return errs.ErrInvalidAppID
+	case "access_restricted":
+		return errs.ErrQuotaExceeded
+	case "not_allowed":
+		return errs.ErrNotAllowed
+	case "invalid_base":
+		return errs.ErrInvalidBase
+	default:
+		return nil
+	}
+}
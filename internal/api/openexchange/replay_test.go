@@ -0,0 +1,84 @@
+package openexchange
+
+import (
+	"context"
+	"errors"
+	"main/internal/api/recorder"
+	"main/internal/errs"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const recordedAPIURL = "https://openexchangerates.org/api/"
+
+// newReplayClient serves the responses recorded in testdata/fixtures, re-record them with
+// recorder.ModeRecord and a real app_id to catch upstream format changes.
+func newReplayClient(t *testing.T) OpenExchange {
+	t.Helper()
+
+	transport, err := recorder.New(recorder.ModeReplay, "testdata/fixtures", nil)
+	if err != nil {
+		t.Fatalf("recorder.New() unexpected error: %v", err)
+	}
+
+	client, err := New(recordedAPIURL, "", WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	return client
+}
+
+func TestOpenExchange_Replay(t *testing.T) {
+	client := newReplayClient(t)
+	ctx := context.Background()
+
+	latest, err := client.GetCurrencyRates(ctx, []string{"EUR", "PLN", "BTC"})
+	if err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if latest.Base != "USD" || latest.Timestamp != 1750240800 ||
+		latest.Rates["PLN"].String() != "3.700355" || latest.Rates["BTC"].String() != "0.000009502683" {
+		t.Errorf("GetCurrencyRates() got = %+v", latest)
+	}
+
+	historical, err := client.GetHistoricalCurrencyRates(
+		ctx, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), []string{"EUR", "GBP"})
+	if err != nil {
+		t.Fatalf("GetHistoricalCurrencyRates() unexpected error: %v", err)
+	}
+
+	if historical.Rates["EUR"].String() != "0.913493" || historical.Rates["GBP"].String() != "0.790512" {
+		t.Errorf("GetHistoricalCurrencyRates() got = %+v", historical)
+	}
+
+	names, err := client.GetCurrencyNames(ctx)
+	if err != nil {
+		t.Fatalf("GetCurrencyNames() unexpected error: %v", err)
+	}
+
+	if names["PLN"] != "Polish Zloty" {
+		t.Errorf("GetCurrencyNames() got = %v", names)
+	}
+
+	usage, err := client.GetUsage(ctx)
+	if err != nil {
+		t.Fatalf("GetUsage() unexpected error: %v", err)
+	}
+
+	if usage.Plan != "Free" || usage.RequestsRemaining != 888 || usage.Features["base"] {
+		t.Errorf("GetUsage() got = %+v", usage)
+	}
+
+	_, err = client.GetCurrencyRatesForBase(ctx, "XYZ", []string{"EUR"})
+	if !errors.Is(err, errs.ErrInvalidBase) {
+		t.Errorf("GetCurrencyRatesForBase() error = %v, want %v", err, errs.ErrInvalidBase)
+	}
+
+	_, err = client.GetHistoricalCurrencyRates(ctx, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	if !errors.Is(err, recorder.ErrNoFixture) {
+		t.Errorf("GetHistoricalCurrencyRates() error = %v, want %v", err, recorder.ErrNoFixture)
+	}
+}
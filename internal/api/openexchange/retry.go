@@ -0,0 +1,177 @@
+package openexchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"main/internal/errs"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// temporaryError marks failures worth retrying: network errors, 5xx and 429 responses.
+type temporaryError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *temporaryError) Unwrap() error {
+	return e.err
+}
+
+// delay returns the wait before the next attempt and false when the upstream
+// asked us to back off for longer than the policy allows.
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	if retryAfter > 0 {
+		return retryAfter, retryAfter <= p.MaxDelay
+	}
+
+	backoff := min(p.BaseDelay<<attempt, p.MaxDelay)
+	if backoff <= 0 {
+		return 0, true
+	}
+
+	half := backoff / 2
+
+	return half + rand.N(backoff-half+1), true //nolint:gosec // jitter does not need crypto rand
+}
+
+func (o OpenExchange) get(ctx context.Context, reqURL *url.URL) ([]byte, error) {
+	if o.breaker != nil {
+		if err := o.breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := o.getWithRetry(ctx, reqURL)
+
+	if o.breaker != nil {
+		var temporary *temporaryError
+
+		// A caller running out of time counts, otherwise a hanging upstream would never open the breaker.
+		// Cancellations and rejected requests (e.g. 401) tell nothing about the upstream health.
+		switch {
+		case err == nil:
+			o.breaker.Success()
+		case errors.As(err, &temporary), errors.Is(ctx.Err(), context.DeadlineExceeded):
+			o.breaker.Failure()
+		default:
+			o.breaker.Release()
+		}
+	}
+
+	return body, err
+}
+
+func (o OpenExchange) getWithRetry(ctx context.Context, reqURL *url.URL) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := o.attempt(ctx, reqURL)
+
+		var temporary *temporaryError
+		if err == nil || !errors.As(err, &temporary) || attempt >= o.retry.MaxRetries {
+			return body, err
+		}
+
+		delay, ok := o.retry.delay(attempt, temporary.retryAfter)
+		if !ok {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, fmt.Errorf("%w: %w", errs.ErrAPIResponse, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (o OpenExchange) attempt(ctx context.Context, reqURL *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request %s: %w", reqURL.Path, err)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		// url.Error repeats the request URL, which carries the app_id.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %w", errs.ErrAPIResponse, err)
+		}
+
+		return nil, &temporaryError{err: fmt.Errorf("%w: %w", errs.ErrAPIResponse, err)}
+	}
+
+	defer resp.Body.Close()
+
+	if path.Base(reqURL.Path) != usageFile {
+		o.requests.Add(1)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &temporaryError{
+			err: fmt.Errorf("%w: error reading response body: %w", errs.ErrAPIResponse, err),
+		}
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return bodyBytes, nil
+	}
+
+	err = parseErrorResponse(resp.StatusCode, bodyBytes)
+
+	// Quota exhaustion is reported with 429 as well, but it will not go away within our retries.
+	if temporaryStatus(resp.StatusCode) && !errors.Is(err, errs.ErrQuotaExceeded) {
+		return nil, &temporaryError{
+			err:        err,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	return nil, err
+}
+
+func temporaryStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// parseRetryAfter understands both forms of the header: delay in seconds and HTTP date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
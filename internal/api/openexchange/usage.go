@@ -0,0 +1,58 @@
+package openexchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/internal/api"
+	"path"
+)
+
+const usageFile = "usage.json"
+
+type usageResponse struct {
+	Data struct {
+		Plan struct {
+			Name     string          `json:"name"`
+			Features map[string]bool `json:"features"`
+		} `json:"plan"`
+		Usage struct {
+			Requests          int `json:"requests"`
+			RequestsQuota     int `json:"requests_quota"`
+			RequestsRemaining int `json:"requests_remaining"`
+			DaysElapsed       int `json:"days_elapsed"`
+			DaysRemaining     int `json:"days_remaining"`
+		} `json:"usage"`
+	} `json:"data"`
+}
+
+func (o OpenExchange) GetUsage(ctx context.Context) (api.Usage, error) {
+	reqURL := *o.URL
+	reqURL.Path = path.Join(path.Dir(o.URL.Path), usageFile)
+
+	query := reqURL.Query()
+	query.Del("base")
+	reqURL.RawQuery = query.Encode()
+
+	bodyBytes, err := o.get(ctx, &reqURL)
+	if err != nil {
+		return api.Usage{}, err
+	}
+
+	var result usageResponse
+
+	err = json.Unmarshal(bodyBytes, &result)
+	if err != nil {
+		return api.Usage{}, fmt.Errorf("error unmarshaling usage body %s: %w", bodyBytes, err)
+	}
+
+	return api.Usage{
+		Plan:              result.Data.Plan.Name,
+		Requests:          result.Data.Usage.Requests,
+		RequestsQuota:     result.Data.Usage.RequestsQuota,
+		RequestsRemaining: result.Data.Usage.RequestsRemaining,
+		DaysElapsed:       result.Data.Usage.DaysElapsed,
+		DaysRemaining:     result.Data.Usage.DaysRemaining,
+		Features:          result.Data.Plan.Features,
+	}, nil
+}
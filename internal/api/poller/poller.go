@@ -0,0 +1,168 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"main/internal/api"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Status struct {
+	LastSuccess         *time.Time `json:"lastSuccess,omitempty"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+}
+
+// Poller refreshes the full rate table in the background and serves requests
+// from the last good snapshot, so the request path never waits for the upstream.
+type Poller struct {
+	provider api.CurrencyRate
+	interval time.Duration
+	now      func() time.Time
+
+	snapshot atomic.Pointer[api.Response]
+
+	mu     sync.RWMutex
+	status Status
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan api.Response]struct{}
+}
+
+func New(provider api.CurrencyRate, interval time.Duration) *Poller {
+	return &Poller{
+		provider:    provider,
+		interval:    interval,
+		now:         time.Now,
+		subscribers: make(map[chan api.Response]struct{}),
+	}
+}
+
+// Run refreshes the snapshot every interval until ctx is cancelled.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Rate poller stopped")
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) GetCurrencyRates(
+	ctx context.Context,
+	currencies []string,
+) (api.Response, error) {
+	snapshot := p.snapshot.Load()
+	if snapshot == nil {
+		// Nothing fetched yet, e.g. the upstream was down at startup.
+		return p.provider.GetCurrencyRates(ctx, currencies)
+	}
+
+	return snapshot.Filter(currencies)
+}
+
+// Subscribe returns a channel receiving the full rate table whenever it changes,
+// a slow subscriber only gets the latest one. cancel stops the updates.
+func (p *Poller) Subscribe() (<-chan api.Response, func()) {
+	updates := make(chan api.Response, 1)
+
+	p.subscribersMu.Lock()
+	p.subscribers[updates] = struct{}{}
+	p.subscribersMu.Unlock()
+
+	cancel := func() {
+		p.subscribersMu.Lock()
+		delete(p.subscribers, updates)
+		p.subscribersMu.Unlock()
+	}
+
+	return updates, cancel
+}
+
+func (p *Poller) Status() Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.status
+}
+
+func (p *Poller) refresh(ctx context.Context) {
+	fetchCtx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+
+	resp, err := p.provider.GetCurrencyRates(fetchCtx, nil)
+	if err != nil {
+		// Shutting down, the failure says nothing about the upstream.
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.fail(fmt.Errorf("error refreshing rate table: %w", err))
+
+		return
+	}
+
+	previous := p.snapshot.Swap(&resp)
+	if previous == nil || changed(*previous, resp) {
+		p.publish(resp)
+	}
+
+	now := p.now()
+
+	p.mu.Lock()
+	p.status = Status{LastSuccess: &now}
+	p.mu.Unlock()
+}
+
+func (p *Poller) fail(err error) {
+	p.mu.Lock()
+	p.status.ConsecutiveFailures++
+	p.status.LastError = err.Error()
+	failures := p.status.ConsecutiveFailures
+	p.mu.Unlock()
+
+	slog.Warn("Rate poller failed, serving last good snapshot",
+		slog.String("error", err.Error()),
+		slog.Int("consecutiveFailures", failures),
+	)
+}
+
+func (p *Poller) publish(table api.Response) {
+	p.subscribersMu.Lock()
+	defer p.subscribersMu.Unlock()
+
+	for updates := range p.subscribers {
+		// Replace the update the subscriber has not picked up yet, refresh is the only sender.
+		select {
+		case <-updates:
+		default:
+		}
+
+		updates <- table
+	}
+}
+
+func changed(previous, current api.Response) bool {
+	if previous.Timestamp != current.Timestamp || len(previous.Rates) != len(current.Rates) {
+		return true
+	}
+
+	for currency, rate := range current.Rates {
+		if previousRate, ok := previous.Rates[currency]; !ok || !previousRate.Equal(rate) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"main/internal/api"
+	"main/internal/errs"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MockCurrencyAPI struct {
+	calls atomic.Int32
+	fail  atomic.Bool
+}
+
+func (m *MockCurrencyAPI) GetCurrencyRates(
+	_ context.Context, currencies []string,
+) (api.Response, error) {
+	m.calls.Add(1)
+
+	if m.fail.Load() {
+		return api.Response{}, errs.ErrAPIResponse
+	}
+
+	resp := api.Response{
+		Base:      "USD",
+		Timestamp: 1750240800,
+		Rates: map[string]decimal.Decimal{
+			"EUR": decimal.RequireFromString("0.869136"),
+			"GBP": decimal.RequireFromString("0.743653"),
+			"USD": decimal.RequireFromString("1"),
+		},
+	}
+
+	return resp.Filter(currencies)
+}
+
+func TestPoller_ServesLastGoodSnapshot(t *testing.T) {
+	provider := &MockCurrencyAPI{}
+	poller := New(provider, time.Hour)
+
+	poller.refresh(context.Background())
+
+	provider.fail.Store(true)
+	poller.refresh(context.Background())
+	poller.refresh(context.Background())
+
+	resp, err := poller.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+	if err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if len(resp.Rates) != 2 {
+		t.Errorf("GetCurrencyRates() got %d rates, want 2", len(resp.Rates))
+	}
+
+	if got := provider.calls.Load(); got != 3 {
+		t.Errorf("provider called %d times, want 3", got)
+	}
+
+	status := poller.Status()
+	if status.LastSuccess == nil || status.ConsecutiveFailures != 2 || status.LastError == "" {
+		t.Errorf("Status() got = %+v, want last success and 2 failures", status)
+	}
+
+	provider.fail.Store(false)
+	poller.refresh(context.Background())
+
+	if status := poller.Status(); status.ConsecutiveFailures != 0 || status.LastError != "" {
+		t.Errorf("Status() after recovery got = %+v, want no failures", status)
+	}
+}
+
+func TestPoller_FallsBackToProviderWithoutSnapshot(t *testing.T) {
+	provider := &MockCurrencyAPI{}
+	provider.fail.Store(true)
+
+	poller := New(provider, time.Hour)
+
+	_, err := poller.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+	if !errors.Is(err, errs.ErrAPIResponse) {
+		t.Errorf("GetCurrencyRates() got err = %v, want %v", err, errs.ErrAPIResponse)
+	}
+}
+
+func TestPoller_RunStopsOnCancel(t *testing.T) {
+	provider := &MockCurrencyAPI{}
+	poller := New(provider, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+
+	go func() {
+		poller.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(35 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not stop after cancel")
+	}
+
+	if got := provider.calls.Load(); got < 2 {
+		t.Errorf("provider called %d times, want at least 2 refreshes", got)
+	}
+
+	if status := poller.Status(); status.ConsecutiveFailures != 0 {
+		t.Errorf("Status() got = %+v, shutdown must not count as failure", status)
+	}
+}
+
+func TestPoller_Subscribe(t *testing.T) {
+	provider := &MockCurrencyAPI{}
+	poller := New(provider, time.Hour)
+
+	updates, cancel := poller.Subscribe()
+
+	poller.refresh(context.Background())
+
+	select {
+	case table := <-updates:
+		if len(table.Rates) != 3 {
+			t.Errorf("update got %d rates, want 3", len(table.Rates))
+		}
+	default:
+		t.Fatalf("no update after the first refresh")
+	}
+
+	poller.refresh(context.Background())
+
+	select {
+	case <-updates:
+		t.Errorf("update published for an unchanged table")
+	default:
+	}
+
+	cancel()
+	poller.snapshot.Store(&api.Response{Timestamp: 1})
+	poller.refresh(context.Background())
+
+	select {
+	case <-updates:
+		t.Errorf("update published after cancel")
+	default:
+	}
+}
@@ -0,0 +1,360 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/api"
+	"main/internal/errs"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// errQuotaExceeded is reported as an upstream failure, so a failover chain moves on to the next provider.
+var errQuotaExceeded = fmt.Errorf("%w: %w", errs.ErrAPIResponse, errs.ErrQuotaExceeded)
+
+type State struct {
+	Usage         *api.Usage `json:"usage,omitempty"`
+	LocalRequests int        `json:"localRequests"`
+	Projected     int        `json:"projected"`
+	Throttled     bool       `json:"throttled"`
+	LastCheck     *time.Time `json:"lastCheck,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+}
+
+// Budget keeps a provider within its plan quota. It syncs usage from the provider
+// periodically and counts requests made in between. When the projected usage for the
+// billing period exceeds the quota, upstream fetches are spread evenly over the time
+// left and the last fetched table is served in between. With no quota left at all,
+// requests are refused unless there is a table to fall back to.
+type Budget struct {
+	provider   api.CurrencyRate
+	usage      api.UsageReporter
+	counter    api.RequestCounter
+	interval   time.Duration
+	thresholds []float64
+	now        func() time.Time
+
+	mu        sync.Mutex
+	current   *api.Usage
+	checkedAt time.Time
+	local     int
+	// synced is the counter value at the last usage check.
+	synced    uint64
+	lastFetch time.Time
+	lastTable *api.Response
+	lastError string
+	warned    map[float64]bool
+}
+
+func New(
+	provider api.CurrencyRate,
+	usage api.UsageReporter,
+	interval time.Duration,
+	thresholds []float64,
+) *Budget {
+	// Providers counting their own requests report retries too, otherwise each call counts once.
+	counter, _ := provider.(api.RequestCounter)
+
+	return &Budget{
+		provider:   provider,
+		usage:      usage,
+		counter:    counter,
+		interval:   interval,
+		thresholds: thresholds,
+		now:        time.Now,
+		warned:     make(map[float64]bool),
+	}
+}
+
+// Run syncs the usage with the provider every interval until ctx is cancelled.
+func (b *Budget) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		b.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *Budget) GetCurrencyRates(
+	ctx context.Context,
+	currencies []string,
+) (api.Response, error) {
+	b.mu.Lock()
+	allowed := b.allowFetch()
+	stale := b.lastTable
+	b.mu.Unlock()
+
+	if !allowed {
+		if stale == nil {
+			return api.Response{}, errQuotaExceeded
+		}
+
+		// The last table may have been fetched for other currencies.
+		resp, err := stale.Filter(currencies)
+		if err != nil {
+			return api.Response{}, errQuotaExceeded
+		}
+
+		return resp, nil
+	}
+
+	resp, err := b.provider.GetCurrencyRates(ctx, currencies)
+
+	b.mu.Lock()
+	if reached(err) {
+		b.count()
+	}
+
+	if err == nil {
+		b.lastTable = &resp
+	}
+	b.mu.Unlock()
+
+	if err != nil {
+		return api.Response{}, fmt.Errorf("error fetching rate table: %w", err)
+	}
+
+	return resp.Filter(currencies)
+}
+
+// GetCurrencyRatesForBase reports errs.ErrNotAllowed while throttled,
+// so callers can rebase the last table locally instead.
+func (b *Budget) GetCurrencyRatesForBase(
+	ctx context.Context,
+	base string,
+	currencies []string,
+) (api.Response, error) {
+	baseProvider, ok := b.provider.(api.BaseCurrencyRate)
+	if !ok {
+		return api.Response{}, errs.ErrNotAllowed
+	}
+
+	b.mu.Lock()
+	allowed := b.allowFetch()
+	b.mu.Unlock()
+
+	if !allowed {
+		return api.Response{}, errs.ErrNotAllowed
+	}
+
+	resp, err := baseProvider.GetCurrencyRatesForBase(ctx, base, currencies)
+	b.countIfReached(err)
+
+	return resp, err
+}
+
+func (b *Budget) GetHistoricalCurrencyRates(
+	ctx context.Context,
+	date time.Time,
+	currencies []string,
+) (api.Response, error) {
+	historical, ok := b.provider.(api.HistoricalCurrencyRate)
+	if !ok {
+		return api.Response{}, errs.ErrHistoryNotSupported
+	}
+
+	b.mu.Lock()
+	allowed := b.allowFetch()
+	b.mu.Unlock()
+
+	if !allowed {
+		return api.Response{}, errQuotaExceeded
+	}
+
+	resp, err := historical.GetHistoricalCurrencyRates(ctx, date, currencies)
+	b.countIfReached(err)
+
+	return resp, err
+}
+
+func (b *Budget) GetCurrencyNames(ctx context.Context) (map[string]string, error) {
+	namesProvider, ok := b.provider.(api.CurrencyNames)
+	if !ok {
+		return nil, errs.ErrNotAllowed
+	}
+
+	b.mu.Lock()
+	allowed := b.allowFetch()
+	b.mu.Unlock()
+
+	if !allowed {
+		return nil, errQuotaExceeded
+	}
+
+	names, err := namesProvider.GetCurrencyNames(ctx)
+	b.countIfReached(err)
+
+	return names, err
+}
+
+func (b *Budget) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tally()
+
+	state := State{
+		LocalRequests: b.local,
+		LastError:     b.lastError,
+	}
+
+	if b.current != nil {
+		usage := *b.current
+		checkedAt := b.checkedAt
+
+		state.Usage = &usage
+		state.LastCheck = &checkedAt
+		state.Projected = b.projected()
+		state.Throttled = !b.allowFetch()
+	}
+
+	return state
+}
+
+func (b *Budget) check(ctx context.Context) {
+	usage, err := b.usage.GetUsage(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("Failed to check provider quota", slog.String("error", err.Error()))
+
+		b.mu.Lock()
+		b.lastError = err.Error()
+		b.mu.Unlock()
+
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A new billing period has started.
+	if b.current != nil && usage.Requests < b.current.Requests {
+		b.warned = make(map[float64]bool)
+	}
+
+	b.current = &usage
+	b.checkedAt = b.now()
+	b.local = 0
+
+	if b.counter != nil {
+		b.synced = b.counter.Requests()
+	}
+	b.lastError = ""
+
+	b.warn()
+}
+
+func (b *Budget) countIfReached(err error) {
+	if !reached(err) {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.count()
+}
+
+// reached tells whether a request got to the provider, only those are billed.
+func reached(err error) bool {
+	var netErr net.Error
+
+	return err == nil || !(errors.As(err, &netErr) ||
+		errors.Is(err, errs.ErrCircuitOpen) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded))
+}
+
+// count must be called with mu held.
+func (b *Budget) count() {
+	if b.counter == nil {
+		b.local++
+	}
+
+	b.tally()
+	b.lastFetch = b.now()
+
+	b.warn()
+}
+
+// tally picks up the requests the provider made since the last check, must be called with mu held.
+func (b *Budget) tally() {
+	if b.counter != nil {
+		b.local = int(b.counter.Requests() - b.synced)
+	}
+}
+
+// allowFetch must be called with mu held.
+func (b *Budget) allowFetch() bool {
+	b.tally()
+
+	if b.current == nil || b.current.RequestsQuota <= 0 {
+		return true
+	}
+
+	remaining := b.current.RequestsQuota - b.used()
+	if remaining <= 0 {
+		return false
+	}
+
+	if b.projected() <= b.current.RequestsQuota {
+		return true
+	}
+
+	timeLeft := time.Duration(b.current.DaysRemaining+1) * day
+	minInterval := timeLeft / time.Duration(remaining)
+
+	return b.now().Sub(b.lastFetch) >= minInterval
+}
+
+// projected extrapolates the average daily usage to the end of the period, must be called with mu held.
+func (b *Budget) projected() int {
+	used := b.used()
+	dailyAverage := float64(used) / float64(max(b.current.DaysElapsed, 1))
+
+	return used + int(math.Ceil(dailyAverage*float64(b.current.DaysRemaining)))
+}
+
+func (b *Budget) used() int {
+	return b.current.Requests + b.local
+}
+
+// warn logs every crossed threshold once per billing period, must be called with mu held.
+func (b *Budget) warn() {
+	if b.current == nil || b.current.RequestsQuota <= 0 {
+		return
+	}
+
+	ratio := float64(b.used()) / float64(b.current.RequestsQuota)
+
+	for _, threshold := range b.thresholds {
+		if ratio < threshold || b.warned[threshold] {
+			continue
+		}
+
+		b.warned[threshold] = true
+
+		slog.Warn("Provider quota threshold reached",
+			slog.Float64("threshold", threshold),
+			slog.Int("requests", b.used()),
+			slog.Int("requestsQuota", b.current.RequestsQuota),
+			slog.Int("projected", b.projected()),
+		)
+	}
+}
@@ -0,0 +1,310 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/api"
+	"main/internal/api/failover"
+	"main/internal/errs"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MockCurrencyAPI struct {
+	calls      int
+	currencies []string
+	err        error
+}
+
+func (m *MockCurrencyAPI) GetCurrencyRates(
+	_ context.Context, currencies []string,
+) (api.Response, error) {
+	m.calls++
+	m.currencies = currencies
+
+	if m.err != nil {
+		return api.Response{}, m.err
+	}
+
+	resp := api.Response{
+		Base:      "USD",
+		Timestamp: 1750240800,
+		Rates:     map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.869136"), "GBP": decimal.RequireFromString("0.743653"), "USD": decimal.RequireFromString("1")},
+	}
+
+	return resp.Filter(currencies)
+}
+
+type MockUsage struct {
+	usage api.Usage
+}
+
+func (m MockUsage) GetUsage(_ context.Context) (api.Usage, error) {
+	return m.usage, nil
+}
+
+func TestBudget_GetCurrencyRates(t *testing.T) {
+	tests := []struct {
+		name          string
+		usage         api.Usage
+		requests      int
+		wantCalls     int
+		wantErr       error
+		wantThrottled bool
+	}{
+		{
+			name: "within budget every request reaches upstream",
+			usage: api.Usage{
+				Requests: 100, RequestsQuota: 1000, DaysElapsed: 10, DaysRemaining: 20,
+			},
+			requests:  3,
+			wantCalls: 3,
+		},
+		{
+			name: "unlimited plan is never throttled",
+			usage: api.Usage{
+				Requests: 100000, RequestsQuota: -1, DaysElapsed: 1, DaysRemaining: 29,
+			},
+			requests:  3,
+			wantCalls: 3,
+		},
+		{
+			name: "projected overrun serves last table between spread fetches",
+			usage: api.Usage{
+				Requests: 900, RequestsQuota: 1000, DaysElapsed: 15, DaysRemaining: 15,
+			},
+			requests:      3,
+			wantCalls:     1,
+			wantThrottled: true,
+		},
+		{
+			name: "exhausted quota without table is refused",
+			usage: api.Usage{
+				Requests: 1000, RequestsQuota: 1000, DaysElapsed: 20, DaysRemaining: 10,
+			},
+			requests:      1,
+			wantCalls:     0,
+			wantErr:       errs.ErrQuotaExceeded,
+			wantThrottled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &MockCurrencyAPI{}
+			budget := New(provider, MockUsage{usage: tt.usage}, time.Hour, []float64{0.8})
+
+			budget.check(context.Background())
+
+			var err error
+
+			for range tt.requests {
+				_, err = budget.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+				if tt.wantErr == nil && err != nil {
+					t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetCurrencyRates() got err = %v, want %v", err, tt.wantErr)
+			}
+
+			if provider.calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", provider.calls, tt.wantCalls)
+			}
+
+			state := budget.State()
+			if state.Throttled != tt.wantThrottled {
+				t.Errorf("State() throttled = %v, want %v", state.Throttled, tt.wantThrottled)
+			}
+
+			if state.LocalRequests != tt.wantCalls {
+				t.Errorf("State() local requests = %d, want %d", state.LocalRequests, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBudget_SpreadsFetchesOverRemainingTime(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	provider := &MockCurrencyAPI{}
+	usage := api.Usage{Requests: 970, RequestsQuota: 1000, DaysElapsed: 14, DaysRemaining: 14}
+
+	budget := New(provider, MockUsage{usage: usage}, time.Hour, nil)
+	budget.now = func() time.Time { return now }
+	budget.check(context.Background())
+
+	for range 2 {
+		if _, err := budget.GetCurrencyRates(context.Background(), []string{"EUR"}); err != nil {
+			t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Fatalf("provider called %d times, want 1", provider.calls)
+	}
+
+	// 29 requests left for 15 days, one fetch roughly every 12.4 hours.
+	now = now.Add(13 * time.Hour)
+
+	if _, err := budget.GetCurrencyRates(context.Background(), []string{"EUR"}); err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if provider.calls != 2 {
+		t.Errorf("provider called %d times, want 2", provider.calls)
+	}
+}
+
+func TestBudget_CountsRequestsReachingProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantLocal int
+	}{
+		{name: "answered request", wantLocal: 1},
+		{name: "error response", err: fmt.Errorf("%w: status 500", errs.ErrAPIResponse), wantLocal: 1},
+		{name: "open breaker", err: errs.ErrCircuitOpen, wantLocal: 0},
+		{name: "network error", err: fmt.Errorf("%w: %w", errs.ErrAPIResponse, &net.OpError{Op: "dial", Err: errors.New("refused")}), wantLocal: 0},
+		{name: "cancelled", err: fmt.Errorf("%w: %w", errs.ErrAPIResponse, context.Canceled), wantLocal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &MockCurrencyAPI{err: tt.err}
+			usage := api.Usage{Requests: 100, RequestsQuota: 1000, DaysElapsed: 10, DaysRemaining: 20}
+
+			budget := New(provider, MockUsage{usage: usage}, time.Hour, nil)
+			budget.check(context.Background())
+
+			_, _ = budget.GetCurrencyRates(context.Background(), []string{"EUR"})
+
+			if got := budget.State().LocalRequests; got != tt.wantLocal {
+				t.Errorf("State() local requests = %d, want %d", got, tt.wantLocal)
+			}
+		})
+	}
+}
+
+func TestBudget_ExhaustedQuotaFailsOver(t *testing.T) {
+	usage := api.Usage{Requests: 1000, RequestsQuota: 1000, DaysElapsed: 20, DaysRemaining: 10}
+
+	budget := New(&MockCurrencyAPI{}, MockUsage{usage: usage}, time.Hour, nil)
+	budget.check(context.Background())
+
+	backup := &MockCurrencyAPI{}
+
+	chain, err := failover.New(
+		failover.Provider{Name: "primary", API: budget, Timeout: time.Second},
+		failover.Provider{Name: "backup", API: backup, Timeout: time.Second},
+	)
+	if err != nil {
+		t.Fatalf("failover.New() unexpected error: %v", err)
+	}
+
+	resp, err := chain.GetCurrencyRates(context.Background(), []string{"EUR", "GBP"})
+	if err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if resp.Provider != "backup" || backup.calls != 1 {
+		t.Errorf("GetCurrencyRates() served by %q after %d backup calls, want backup", resp.Provider, backup.calls)
+	}
+}
+
+func TestBudget_PassesCurrenciesThrough(t *testing.T) {
+	provider := &MockCurrencyAPI{}
+	usage := api.Usage{Requests: 900, RequestsQuota: 1000, DaysElapsed: 15, DaysRemaining: 15}
+
+	budget := New(provider, MockUsage{usage: usage}, time.Hour, nil)
+	budget.check(context.Background())
+
+	if _, err := budget.GetCurrencyRates(context.Background(), []string{"EUR"}); err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(provider.currencies, []string{"EUR"}) {
+		t.Errorf("provider asked for %v, want [EUR]", provider.currencies)
+	}
+
+	// Throttled now, the last table has no GBP to serve.
+	_, err := budget.GetCurrencyRates(context.Background(), []string{"GBP"})
+	if !errors.Is(err, errs.ErrQuotaExceeded) || !errors.Is(err, errs.ErrAPIResponse) {
+		t.Errorf("GetCurrencyRates() got err = %v, want %v", err, errs.ErrQuotaExceeded)
+	}
+}
+
+// MockCountingAPI makes several requests per call, like a client retrying.
+type MockCountingAPI struct {
+	MockCurrencyAPI
+	perCall  uint64
+	requests uint64
+}
+
+func (m *MockCountingAPI) GetCurrencyRates(ctx context.Context, currencies []string) (api.Response, error) {
+	m.requests += m.perCall
+
+	return m.MockCurrencyAPI.GetCurrencyRates(ctx, currencies)
+}
+
+func (m *MockCountingAPI) GetCurrencyNames(_ context.Context) (map[string]string, error) {
+	m.requests += m.perCall
+
+	return map[string]string{"EUR": "Euro"}, m.err
+}
+
+func (m *MockCountingAPI) Requests() uint64 {
+	return m.requests
+}
+
+func TestBudget_CountsProviderRequests(t *testing.T) {
+	provider := &MockCountingAPI{perCall: 3}
+	usage := api.Usage{Requests: 100, RequestsQuota: 1000, DaysElapsed: 10, DaysRemaining: 20}
+
+	// Requests made before the first check are part of the synced usage.
+	provider.requests = 7
+
+	budget := New(provider, MockUsage{usage: usage}, time.Hour, nil)
+	budget.check(context.Background())
+
+	if _, err := budget.GetCurrencyRates(context.Background(), []string{"EUR"}); err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if _, err := budget.GetCurrencyNames(context.Background()); err != nil {
+		t.Fatalf("GetCurrencyNames() unexpected error: %v", err)
+	}
+
+	if got := budget.State().LocalRequests; got != 6 {
+		t.Errorf("State() local requests = %d, want 6", got)
+	}
+
+	budget.check(context.Background())
+
+	if got := budget.State().LocalRequests; got != 0 {
+		t.Errorf("State() local requests after check = %d, want 0", got)
+	}
+}
+
+func TestBudget_GetCurrencyNames(t *testing.T) {
+	provider := &MockCountingAPI{perCall: 1}
+	usage := api.Usage{Requests: 1000, RequestsQuota: 1000, DaysElapsed: 20, DaysRemaining: 10}
+
+	budget := New(provider, MockUsage{usage: usage}, time.Hour, nil)
+	budget.check(context.Background())
+
+	_, err := budget.GetCurrencyNames(context.Background())
+	if !errors.Is(err, errs.ErrQuotaExceeded) {
+		t.Errorf("GetCurrencyNames() got err = %v, want %v", err, errs.ErrQuotaExceeded)
+	}
+
+	if provider.requests != 0 {
+		t.Errorf("provider made %d requests, want none", provider.requests)
+	}
+}
@@ -0,0 +1,184 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+
+	// secretParam is the openexchangerates credential, it never ends up in a fixture.
+	secretParam = "app_id"
+)
+
+var ErrNoFixture = errors.New("error no recorded fixture")
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+type fixture struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"`
+}
+
+// Transport records upstream responses to fixture files in dir, or serves them back
+// without touching the network. Fixtures are keyed by method, path and query, without the app_id.
+type Transport struct {
+	mode string
+	dir  string
+	next http.RoundTripper
+
+	mu sync.Mutex
+}
+
+// New returns a transport in the given mode, next performs the real requests while recording.
+func New(mode, dir string, next http.RoundTripper) (*Transport, error) {
+	switch mode {
+	case ModeRecord, ModeReplay:
+	default:
+		return nil, fmt.Errorf("unknown fixtures mode %q", mode)
+	}
+
+	if dir == "" {
+		return nil, errors.New("fixtures dir is required")
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Transport{
+		mode: mode,
+		dir:  dir,
+		next: next,
+	}, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.dir, fixtureName(req))
+
+	if t.mode == ModeReplay {
+		return t.replay(req, path)
+	}
+
+	return t.record(req, path)
+}
+
+func (t *Transport) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s %s: %w", ErrNoFixture, req.Method, req.URL.Path, err)
+	}
+
+	var recorded fixture
+
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, fmt.Errorf("error parsing fixture %s: %w", path, err)
+	}
+
+	body := []byte(recorded.Text)
+
+	if len(recorded.Body) > 0 {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, recorded.Body); err != nil {
+			return nil, fmt.Errorf("error parsing fixture %s body: %w", path, err)
+		}
+
+		body = compacted.Bytes()
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) record(req *http.Request, path string) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorded := fixture{
+		Status: resp.StatusCode,
+		Header: recordedHeader(resp.Header),
+	}
+
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		recorded.Body = indented.Bytes()
+	} else {
+		recorded.Text = string(body)
+	}
+
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding fixture: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating fixtures dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("error writing fixture: %w", err)
+	}
+
+	return resp, nil
+}
+
+// recordedHeader keeps only the headers the client acts upon.
+func recordedHeader(header http.Header) http.Header {
+	kept := http.Header{}
+
+	for _, key := range []string{"Content-Type", "Retry-After"} {
+		if value := header.Get(key); value != "" {
+			kept.Set(key, value)
+		}
+	}
+
+	return kept
+}
+
+func fixtureName(req *http.Request) string {
+	query := req.URL.Query()
+	query.Del(secretParam)
+
+	name := req.Method + "_" + strings.Trim(req.URL.Path, "/")
+	if encoded := query.Encode(); encoded != "" {
+		name += "_" + encoded
+	}
+
+	return unsafeChars.ReplaceAllString(name, "_") + ".fixture"
+}
@@ -0,0 +1,117 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransport_RecordReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/latest.json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"base":"USD","timestamp":1750240800,"rates":{"EUR":0.869136,"USD":1}}`))
+		default:
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`slow down`))
+		}
+	}))
+
+	recorder, err := New(ModeRecord, dir, nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	urls := []string{
+		srv.URL + "/api/latest.json?app_id=secret-id&base=USD",
+		srv.URL + "/api/usage.json?app_id=secret-id",
+	}
+
+	recorded := make([]string, 0, len(urls))
+
+	for _, u := range urls {
+		recorded = append(recorded, get(t, recorder, u))
+	}
+
+	srv.Close()
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != len(urls) {
+		t.Fatalf("ReadDir() got %d files, err %v, want %d", len(files), err, len(urls))
+	}
+
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+
+		if strings.Contains(f.Name()+string(data), "secret-id") {
+			t.Errorf("fixture %s leaks the app_id", f.Name())
+		}
+	}
+
+	replayer, err := New(ModeReplay, dir, nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	for i, u := range urls {
+		// The server is gone and the app_id differs, the fixture must still match.
+		replayed := get(t, replayer, strings.Replace(u, "secret-id", "other-id", 1))
+		if replayed != recorded[i] {
+			t.Errorf("replay %s got = %s, want %s", u, replayed, recorded[i])
+		}
+	}
+
+	client := &http.Client{Transport: replayer}
+
+	_, err = client.Get(srv.URL + "/api/historical/2024-01-02.json")
+	if !errors.Is(err, ErrNoFixture) {
+		t.Errorf("replay of unrecorded request error = %v, want %v", err, ErrNoFixture)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("live", "testdata", nil); err == nil {
+		t.Errorf("New() expected error for unknown mode")
+	}
+
+	if _, err := New(ModeReplay, "", nil); err == nil {
+		t.Errorf("New() expected error for empty dir")
+	}
+}
+
+// get returns the status, Retry-After and body of the response as one comparable string.
+func get(t *testing.T, transport http.RoundTripper, u string) string {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		t.Fatalf("Do(%s) error = %v", u, err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	return resp.Status + "|" + resp.Header.Get("Retry-After") + "|" + strings.Join(strings.Fields(string(body)), "")
+}
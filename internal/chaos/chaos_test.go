@@ -0,0 +1,185 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/repository/memory"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MockCurrencyAPI struct {
+	calls int
+}
+
+func (m *MockCurrencyAPI) GetCurrencyRates(
+	_ context.Context,
+	currencies []string,
+) (api.Response, error) {
+	m.calls++
+
+	resp := api.Response{
+		Base: "USD",
+		Rates: map[string]decimal.Decimal{
+			"EUR": decimal.RequireFromString("0.869136"),
+			"GBP": decimal.RequireFromString("0.743653"),
+			"USD": decimal.NewFromInt(1),
+		},
+	}
+
+	return resp.Filter(currencies)
+}
+
+// newTestInjector always injects the given fault.
+func newTestInjector(t *testing.T, fault string) *Injector {
+	t.Helper()
+
+	injector, err := NewInjector(Settings{Enabled: true, Fraction: 1, LatencyMs: 20, Faults: []string{fault}})
+	if err != nil {
+		t.Fatalf("NewInjector() unexpected error: %v", err)
+	}
+
+	injector.draw = func() float64 { return 0 }
+
+	return injector
+}
+
+func TestProvider_GetCurrencyRates(t *testing.T) {
+	tests := []struct {
+		name      string
+		fault     string
+		timeout   time.Duration
+		wantErr   error
+		wantCalls int
+		check     func(api.Response) bool
+	}{
+		{
+			name:      "latency",
+			fault:     FaultLatency,
+			timeout:   time.Second,
+			wantCalls: 1,
+			check:     func(r api.Response) bool { return len(r.Rates) == 2 },
+		},
+		{
+			name:    "latency over the deadline",
+			fault:   FaultLatency,
+			timeout: 5 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "error",
+			fault:   FaultError,
+			timeout: time.Second,
+			wantErr: errs.ErrAPIResponse,
+		},
+		{
+			name:      "zero rate",
+			fault:     FaultZero,
+			timeout:   time.Second,
+			wantCalls: 1,
+			check:     func(r api.Response) bool { return r.Rates["EUR"].IsZero() && !r.Rates["GBP"].IsZero() },
+		},
+		{
+			name:      "missing currency",
+			fault:     FaultMissing,
+			timeout:   time.Second,
+			wantCalls: 1,
+			check: func(r api.Response) bool {
+				_, ok := r.Rates["EUR"]
+
+				return !ok && len(r.Rates) == 1
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &MockCurrencyAPI{}
+			provider := NewProvider(upstream, newTestInjector(t, tt.fault))
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			resp, err := provider.GetCurrencyRates(ctx, []string{"EUR", "GBP"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCurrencyRates() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if upstream.calls != tt.wantCalls {
+				t.Errorf("upstream calls got = %d, want %d", upstream.calls, tt.wantCalls)
+			}
+
+			if tt.check != nil && !tt.check(resp) {
+				t.Errorf("GetCurrencyRates() got = %v", resp.Rates)
+			}
+		})
+	}
+}
+
+func TestInjector_Toggle(t *testing.T) {
+	injector := newTestInjector(t, FaultError)
+	upstream := &MockCurrencyAPI{}
+	provider := NewProvider(upstream, injector)
+
+	if _, err := provider.GetCurrencyRates(context.Background(), nil); !errors.Is(err, ErrInjected) {
+		t.Fatalf("GetCurrencyRates() error = %v, want %v", err, ErrInjected)
+	}
+
+	settings := injector.Settings()
+	settings.Enabled = false
+
+	if err := injector.Update(settings); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+
+	if _, err := provider.GetCurrencyRates(context.Background(), nil); err != nil {
+		t.Errorf("GetCurrencyRates() after disabling error = %v", err)
+	}
+
+	invalid := []Settings{
+		{Fraction: 1.5},
+		{Fraction: 0.5, LatencyMs: -1},
+		{Fraction: 0.5, Faults: []string{"meteor"}},
+	}
+
+	for _, s := range invalid {
+		if err := injector.Update(s); err == nil {
+			t.Errorf("Update(%+v) expected error", s)
+		}
+	}
+
+	if injector.Settings().Enabled {
+		t.Errorf("invalid Update() changed the settings")
+	}
+}
+
+func TestRepository_Get(t *testing.T) {
+	tests := []struct {
+		fault   string
+		wantErr error
+	}{
+		{fault: FaultLatency},
+		{fault: FaultError, wantErr: ErrInjected},
+		{fault: FaultMissing, wantErr: errs.ErrRepoCurrencyNotFound},
+		{fault: FaultZero},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fault, func(t *testing.T) {
+			repo := NewRepository(memory.NewCurrencyRateRepo(), newTestInjector(t, tt.fault))
+
+			details, err := repo.Get("GATE")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && details.Rate.IsZero() != (tt.fault == FaultZero) {
+				t.Errorf("Get() got rate %v", details.Rate)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	FaultLatency = "latency"
+	FaultError   = "error"
+	FaultZero    = "zero"
+	FaultMissing = "missing"
+)
+
+var ErrInjected = errors.New("error injected by chaos testing")
+
+// Settings decide which faults are injected. A Fraction of calls gets one of the Faults,
+// drawn at random, while Enabled.
+type Settings struct {
+	Enabled   bool     `json:"enabled"`
+	Fraction  float64  `json:"fraction"`
+	LatencyMs int      `json:"latencyMs"`
+	Faults    []string `json:"faults"`
+}
+
+func (s Settings) validate() error {
+	if s.Fraction < 0 || s.Fraction > 1 {
+		return fmt.Errorf("fraction %v out of [0, 1]", s.Fraction)
+	}
+
+	if s.LatencyMs < 0 {
+		return fmt.Errorf("negative latency %d", s.LatencyMs)
+	}
+
+	for _, fault := range s.Faults {
+		switch fault {
+		case FaultLatency, FaultError, FaultZero, FaultMissing:
+		default:
+			return fmt.Errorf("unknown fault %q", fault)
+		}
+	}
+
+	return nil
+}
+
+// Injector is shared by all wrapped providers and repositories, so one switch controls them all.
+type Injector struct {
+	mu       sync.RWMutex
+	settings Settings
+
+	draw func() float64
+	pick func(n int) int
+}
+
+func NewInjector(settings Settings) (*Injector, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Injector{
+		settings: settings,
+		draw:     rand.Float64, //nolint:gosec // chaos, not security
+		pick:     rand.IntN,    //nolint:gosec // chaos, not security
+	}, nil
+}
+
+func (i *Injector) Settings() Settings {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	settings := i.settings
+	settings.Faults = slices.Clone(settings.Faults)
+
+	return settings
+}
+
+func (i *Injector) Update(settings Settings) error {
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	i.settings = settings
+	i.mu.Unlock()
+
+	return nil
+}
+
+// fault returns the fault to inject into the current call, empty when none.
+func (i *Injector) fault() (string, time.Duration) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if !i.settings.Enabled || len(i.settings.Faults) == 0 || i.draw() >= i.settings.Fraction {
+		return "", 0
+	}
+
+	return i.settings.Faults[i.pick(len(i.settings.Faults))], time.Duration(i.settings.LatencyMs) * time.Millisecond
+}
@@ -0,0 +1,109 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"main/internal/api"
+	"main/internal/errs"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Provider injects faults into the calls to the wrapped currency rate provider.
+type Provider struct {
+	provider api.CurrencyRate
+	injector *Injector
+}
+
+func NewProvider(provider api.CurrencyRate, injector *Injector) *Provider {
+	return &Provider{
+		provider: provider,
+		injector: injector,
+	}
+}
+
+func (p *Provider) GetCurrencyRates(
+	ctx context.Context,
+	currencies []string,
+) (api.Response, error) {
+	return p.inject(ctx, currencies, func() (api.Response, error) {
+		return p.provider.GetCurrencyRates(ctx, currencies)
+	})
+}
+
+func (p *Provider) GetCurrencyRatesForBase(
+	ctx context.Context,
+	base string,
+	currencies []string,
+) (api.Response, error) {
+	baseProvider, ok := p.provider.(api.BaseCurrencyRate)
+	if !ok {
+		return api.Response{}, errs.ErrNotAllowed
+	}
+
+	return p.inject(ctx, currencies, func() (api.Response, error) {
+		return baseProvider.GetCurrencyRatesForBase(ctx, base, currencies)
+	})
+}
+
+func (p *Provider) GetHistoricalCurrencyRates(
+	ctx context.Context,
+	date time.Time,
+	currencies []string,
+) (api.Response, error) {
+	historical, ok := p.provider.(api.HistoricalCurrencyRate)
+	if !ok {
+		return api.Response{}, errs.ErrHistoryNotSupported
+	}
+
+	return p.inject(ctx, currencies, func() (api.Response, error) {
+		return historical.GetHistoricalCurrencyRates(ctx, date, currencies)
+	})
+}
+
+func (p *Provider) inject(
+	ctx context.Context,
+	currencies []string,
+	call func() (api.Response, error),
+) (api.Response, error) {
+	fault, latency := p.injector.fault()
+
+	switch fault {
+	case FaultLatency:
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return api.Response{}, ctx.Err()
+		}
+	case FaultError:
+		return api.Response{}, fmt.Errorf("%w: %w", errs.ErrAPIResponse, ErrInjected)
+	}
+
+	resp, err := call()
+	if err != nil || len(resp.Rates) == 0 {
+		return resp, err
+	}
+
+	switch fault {
+	case FaultZero:
+		resp.Rates = maps.Clone(resp.Rates)
+		resp.Rates[victim(resp.Rates, currencies)] = decimal.Zero
+	case FaultMissing:
+		resp.Rates = maps.Clone(resp.Rates)
+		delete(resp.Rates, victim(resp.Rates, currencies))
+	}
+
+	return resp, nil
+}
+
+// victim picks the currency to corrupt, the first requested one so the damage is visible.
+func victim(rates map[string]decimal.Decimal, currencies []string) string {
+	if len(currencies) > 0 {
+		return currencies[0]
+	}
+
+	return slices.Min(slices.Collect(maps.Keys(rates)))
+}
@@ -0,0 +1,48 @@
+package chaos
+
+import (
+	"fmt"
+	"main/internal/errs"
+	"main/internal/repository"
+	"main/internal/repository/memory"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Repository injects faults into the lookups of the wrapped currency rate repository.
+type Repository struct {
+	repo     repository.CurrencyRate
+	injector *Injector
+}
+
+func NewRepository(repo repository.CurrencyRate, injector *Injector) *Repository {
+	return &Repository{
+		repo:     repo,
+		injector: injector,
+	}
+}
+
+func (r *Repository) Get(currency string) (memory.CurrencyDetails, error) {
+	fault, latency := r.injector.fault()
+
+	switch fault {
+	case FaultLatency:
+		time.Sleep(latency)
+	case FaultError:
+		return memory.CurrencyDetails{}, fmt.Errorf("failed to read currency %s: %w", currency, ErrInjected)
+	case FaultMissing:
+		return memory.CurrencyDetails{}, errs.ErrRepoCurrencyNotFound
+	}
+
+	details, err := r.repo.Get(currency)
+	if err != nil {
+		return details, err
+	}
+
+	if fault == FaultZero {
+		details.Rate = decimal.Zero
+	}
+
+	return details, nil
+}
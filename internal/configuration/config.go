@@ -19,8 +19,100 @@ type Configuration struct {
 	ReadTimeout    time.Duration
 	WriteTimeout   time.Duration
 	ContextTimeout time.Duration
-	APIURL         string
+	FetchTimeout   time.Duration
+	Providers      []Provider
 	LogErrors      bool
+	CacheTTL       time.Duration
+	PollInterval   time.Duration
+	TimeSeries     TimeSeries
+	Stream         Stream
+	WebSocket      WebSocket
+	Chaos          Chaos
+	Alerts         Alerts
+	Repository     Repository
+	Admin          Admin
+}
+
+type Admin struct {
+	TokenEnv string
+}
+
+type Repository struct {
+	Type       string
+	Path       string
+	TokensPath string
+}
+
+type Alerts struct {
+	StorePath        string
+	SecretEnv        string
+	MaxAttempts      int
+	RetryBaseDelayMs time.Duration
+	WebhookTimeout   time.Duration
+	MaxDeadLetters   int
+	AllowedHosts     []string
+}
+
+type WebSocket struct {
+	MaxSubscriptions int
+	MaxPending       int
+	WriteTimeout     time.Duration
+	PingInterval     time.Duration
+	AllowedOrigins   []string
+}
+
+type Stream struct {
+	HeartbeatInterval time.Duration
+	CheckInterval     time.Duration
+}
+
+type Chaos struct {
+	Enabled   bool
+	Fraction  float64
+	LatencyMs int
+	Faults    []string
+}
+
+type TimeSeries struct {
+	MaxDays     int
+	Concurrency int
+}
+
+type Provider struct {
+	Name           string
+	Type           string
+	APIURL         string
+	AppIDEnv       string
+	Path           string
+	WatchInterval  time.Duration
+	Base           string
+	Symbols        bool
+	Timeout        time.Duration
+	Retry          Retry
+	CircuitBreaker CircuitBreaker
+	Quota          Quota
+	Fixtures       Fixtures
+}
+
+type Fixtures struct {
+	Mode string
+	Dir  string
+}
+
+type Retry struct {
+	MaxRetries  int
+	BaseDelayMs time.Duration
+	MaxDelayMs  time.Duration
+}
+
+type Quota struct {
+	CheckInterval  time.Duration
+	WarnThresholds []float64
+}
+
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
 }
 
 func (c *Configuration) Pretty() string {
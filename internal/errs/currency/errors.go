@@ -21,15 +21,41 @@ func (e ErrorHandler) Handle(c *gin.Context, err error) {
 		e.sendErrorResponse(c, http.StatusGatewayTimeout, "currency rate API timeout")
 	case errors.Is(err, errs.ErrCurrencyNotFound):
 		e.sendErrorResponse(c, http.StatusNotFound, errs.ErrCurrencyNotFound.Error())
-	case errors.Is(err, errs.ErrAPIResponse),
-		errors.Is(err, errs.ErrRepoCurrencyNotFound),
+	case errors.Is(err, errs.ErrInvalidAppID):
+		e.sendErrorResponse(c, http.StatusBadGateway, errs.ErrInvalidAppID.Error())
+	case errors.Is(err, errs.ErrNotAllowed):
+		e.sendErrorResponse(c, http.StatusServiceUnavailable, errs.ErrNotAllowed.Error())
+	case errors.Is(err, errs.ErrQuotaExceeded):
+		e.sendErrorResponse(c, http.StatusTooManyRequests, errs.ErrQuotaExceeded.Error())
+	case errors.Is(err, errs.ErrInvalidBase):
+		e.sendErrorResponse(c, http.StatusBadRequest, errs.ErrInvalidBase.Error())
+	case errors.Is(err, errs.ErrAlertNotFound):
+		e.sendErrorResponse(c, http.StatusNotFound, errs.ErrAlertNotFound.Error())
+	case errors.Is(err, errs.ErrInvalidAlert):
+		e.sendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errs.ErrTokenNotFound):
+		e.sendErrorResponse(c, http.StatusNotFound, errs.ErrTokenNotFound.Error())
+	case errors.Is(err, errs.ErrTokenExists),
+		errors.Is(err, errs.ErrVersionConflict):
+		e.sendErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, errs.ErrInvalidToken):
+		e.sendErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errs.ErrCircuitOpen):
+		e.sendErrorResponse(c, http.StatusServiceUnavailable, errs.ErrCircuitOpen.Error())
+	case errors.Is(err, errs.ErrRepoCurrencyNotFound),
 		errors.Is(err, errs.ErrNegativeAmount),
 		errors.Is(err, errs.ErrAmountNotNumber),
 		errors.Is(err, errs.ErrEmptyParam),
+		errors.Is(err, errs.ErrInvalidDate),
+		errors.Is(err, errs.ErrInvalidDateRange),
 		errors.Is(err, errs.ErrBadRequest):
 		e.sendErrorResponse(c, http.StatusBadRequest, "")
 	case errors.Is(err, errs.ErrZeroValue):
 		e.sendErrorResponse(c, http.StatusUnprocessableEntity, errs.ErrZeroValue.Error())
+	case errors.Is(err, errs.ErrHistoryNotSupported):
+		e.sendErrorResponse(c, http.StatusNotImplemented, errs.ErrHistoryNotSupported.Error())
+	case errors.Is(err, errs.ErrAPIResponse):
+		e.sendErrorResponse(c, http.StatusBadGateway, "")
 	default:
 		e.sendErrorResponse(c, http.StatusInternalServerError, err.Error())
 	}
@@ -15,6 +15,20 @@ var (
 	ErrEmptyParam           = errors.New("error one or more params is empty")
 	ErrAmountNotNumber      = errors.New("error amount must a number")
 	ErrZeroValue            = errors.New("error got zero value from API or Repository")
+	ErrInvalidDate          = errors.New("error date must be a past day in YYYY-MM-DD format")
+	ErrInvalidDateRange     = errors.New("error start must not be after end and range must not be too long")
+	ErrInvalidAppID         = errors.New("error currency rate API rejected our credentials")
+	ErrQuotaExceeded        = errors.New("error currency rate API quota exceeded")
+	ErrNotAllowed           = errors.New("error currency rate API plan does not allow this request")
+	ErrInvalidBase          = errors.New("error base currency is not supported")
+	ErrCircuitOpen          = errors.New("error currency rate API is temporarily unavailable")
+	ErrHistoryNotSupported  = errors.New("error historical rates are not supported by the provider")
+	ErrAlertNotFound        = errors.New("error alert not found")
+	ErrInvalidAlert         = errors.New("error invalid alert")
+	ErrTokenNotFound        = errors.New("error token not found")
+	ErrTokenExists          = errors.New("error token already exists")
+	ErrInvalidToken         = errors.New("error invalid token")
+	ErrVersionConflict      = errors.New("error token was changed since the given version")
 )
 
 type ErrorHandler interface {
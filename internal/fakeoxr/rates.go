@@ -0,0 +1,82 @@
+package fakeoxr
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultRates is a snapshot of real USD quoted rates, close enough for development.
+func DefaultRates() map[string]decimal.Decimal {
+	return map[string]decimal.Decimal{
+		"AED":  decimal.RequireFromString("3.6725"),
+		"AUD":  decimal.RequireFromString("1.539832"),
+		"BDT":  decimal.RequireFromString("122.251634"),
+		"BHD":  decimal.RequireFromString("0.377252"),
+		"BTC":  decimal.RequireFromString("0.000009502683"),
+		"CAD":  decimal.RequireFromString("1.367395"),
+		"CHF":  decimal.RequireFromString("0.818142"),
+		"CNY":  decimal.RequireFromString("7.1863"),
+		"CZK":  decimal.RequireFromString("21.5515"),
+		"EUR":  decimal.RequireFromString("0.869136"),
+		"GBP":  decimal.RequireFromString("0.743653"),
+		"INR":  decimal.RequireFromString("86.466554"),
+		"IRR":  decimal.RequireFromString("42125"),
+		"JPY":  decimal.RequireFromString("145.19025"),
+		"MRU":  decimal.RequireFromString("39.6"),
+		"NOK":  decimal.RequireFromString("10.01655"),
+		"PLN":  decimal.RequireFromString("3.700355"),
+		"SEK":  decimal.RequireFromString("9.539725"),
+		"USD":  decimal.NewFromInt(1),
+		"USDT": decimal.RequireFromString("1.000235"),
+	}
+}
+
+func DefaultNames() map[string]string {
+	return map[string]string{
+		"AED":  "United Arab Emirates Dirham",
+		"AUD":  "Australian Dollar",
+		"BDT":  "Bangladeshi Taka",
+		"BHD":  "Bahraini Dinar",
+		"BTC":  "Bitcoin",
+		"CAD":  "Canadian Dollar",
+		"CHF":  "Swiss Franc",
+		"CNY":  "Chinese Yuan",
+		"CZK":  "Czech Republic Koruna",
+		"EUR":  "Euro",
+		"GBP":  "British Pound Sterling",
+		"INR":  "Indian Rupee",
+		"IRR":  "Iranian Rial",
+		"JPY":  "Japanese Yen",
+		"MRU":  "Mauritanian Ouguiya",
+		"NOK":  "Norwegian Krone",
+		"PLN":  "Polish Zloty",
+		"SEK":  "Swedish Krona",
+		"USD":  "United States Dollar",
+		"USDT": "Tether",
+	}
+}
+
+// roundRate rounds to ratePrecision decimals, but keeps as many significant
+// digits for tiny rates like BTC instead of rounding them to zero.
+func roundRate(rate decimal.Decimal) decimal.Decimal {
+	places := int32(ratePrecision)
+
+	if value := rate.InexactFloat64(); value > 0 && value < 1 {
+		places = max(places, int32(ratePrecision-1-math.Floor(math.Log10(value))))
+	}
+
+	return rate.Round(places)
+}
+
+// numbers renders rates as JSON numbers, the way openexchangerates does.
+func numbers(rates map[string]decimal.Decimal) map[string]json.Number {
+	rendered := make(map[string]json.Number, len(rates))
+
+	for currency, rate := range rates {
+		rendered[currency] = json.Number(rate.String())
+	}
+
+	return rendered
+}
@@ -0,0 +1,362 @@
+package fakeoxr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	base       = "USD"
+	dateLayout = "2006-01-02"
+
+	// ratePrecision is the number of decimals openexchangerates publishes.
+	ratePrecision = 6
+
+	// historicalVolatility is how far past tables drift from the configured rates.
+	historicalVolatility = 0.02
+
+	// ErrorHeader forces a single response to fail with one of the error kinds, e.g. for tests.
+	ErrorHeader = "X-Fake-Error"
+
+	ErrorUnauthorized = "401"
+	ErrorRateLimit    = "429"
+	ErrorServer       = "500"
+	ErrorUnavailable  = "503"
+	ErrorMalformed    = "malformed"
+)
+
+type Config struct {
+	// AppID is the only accepted app_id, any non-empty one is accepted when empty.
+	AppID string
+	// Rates are quoted in USD, DefaultRates when empty.
+	Rates map[string]decimal.Decimal
+	Names map[string]string
+	// Latency delays every response.
+	Latency time.Duration
+	// Errors maps an error kind to the probability of a request failing with it.
+	Errors map[string]float64
+	// Volatility is the standard deviation of the relative rate change per WalkInterval.
+	Volatility   float64
+	WalkInterval time.Duration
+	// AllowBase and AllowSymbols emulate the plan features, both are off on the free plan.
+	AllowBase    bool
+	AllowSymbols bool
+	// RequestsQuota is the monthly plan quota reported by usage.json, requests over it get 429.
+	RequestsQuota int
+	Seed          uint64
+}
+
+// Server mimics the openexchangerates.org API endpoints used by the currency API.
+type Server struct {
+	cfg Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	rng       *rand.Rand
+	rates     map[string]decimal.Decimal
+	timestamp time.Time
+	requests  int
+}
+
+func New(cfg Config) *Server {
+	if len(cfg.Rates) == 0 {
+		cfg.Rates = DefaultRates()
+	}
+
+	if len(cfg.Names) == 0 {
+		cfg.Names = DefaultNames()
+	}
+
+	rates := maps.Clone(cfg.Rates)
+	rates[base] = decimal.NewFromInt(1)
+
+	return &Server{
+		cfg:       cfg,
+		now:       time.Now,
+		rng:       rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)), //nolint:gosec // fake prices, not security
+		rates:     rates,
+		timestamp: time.Now(),
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	api := router.Group("/api", s.simulate)
+	api.GET("/latest.json", s.authorize, s.limit, s.latest)
+	api.GET("/historical/:date", s.authorize, s.limit, s.historical)
+	api.GET("/usage.json", s.authorize, s.usage)
+	api.GET("/currencies.json", s.currencies)
+
+	return router
+}
+
+// Run moves the rates in a random walk every WalkInterval until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) {
+	if s.cfg.WalkInterval <= 0 || s.cfg.Volatility <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.WalkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.walk()
+		}
+	}
+}
+
+func (s *Server) walk() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for currency, rate := range s.rates {
+		if currency == base {
+			continue
+		}
+
+		change := math.Exp(s.rng.NormFloat64() * s.cfg.Volatility)
+		s.rates[currency] = roundRate(rate.Mul(decimal.NewFromFloat(change)))
+	}
+
+	s.timestamp = s.now()
+}
+
+// simulate applies the configured latency and error injection.
+func (s *Server) simulate(c *gin.Context) {
+	if s.cfg.Latency > 0 {
+		select {
+		case <-time.After(s.cfg.Latency):
+		case <-c.Request.Context().Done():
+			c.Abort()
+
+			return
+		}
+	}
+
+	kind := c.GetHeader(ErrorHeader)
+	if kind == "" {
+		kind = s.drawError()
+	}
+
+	if kind != "" {
+		slog.Info("Injecting error", slog.String("kind", kind), slog.String("path", c.Request.URL.Path))
+		injectError(c, kind)
+	}
+}
+
+func (s *Server) drawError() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draw := s.rng.Float64()
+
+	for _, kind := range []string{ErrorUnauthorized, ErrorRateLimit, ErrorServer, ErrorUnavailable, ErrorMalformed} {
+		draw -= s.cfg.Errors[kind]
+		if draw < 0 {
+			return kind
+		}
+	}
+
+	return ""
+}
+
+func injectError(c *gin.Context, kind string) {
+	switch kind {
+	case ErrorUnauthorized:
+		abortWithError(c, http.StatusUnauthorized, "invalid_app_id", "Invalid App ID provided.")
+	case ErrorRateLimit:
+		c.Header("Retry-After", "1")
+		abortWithError(c, http.StatusTooManyRequests, "too_many_requests", "Too many requests, slow down.")
+	case ErrorServer:
+		c.AbortWithStatus(http.StatusInternalServerError)
+	case ErrorUnavailable:
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+	case ErrorMalformed:
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(`{"base":"USD","rates":{"EUR":0.8`))
+		c.Abort()
+	default:
+		abortWithError(c, http.StatusBadRequest, "invalid_fake_error", fmt.Sprintf("Unknown error kind %q.", kind))
+	}
+}
+
+func (s *Server) authorize(c *gin.Context) {
+	appID := c.Query("app_id")
+
+	switch {
+	case appID == "":
+		abortWithError(c, http.StatusUnauthorized, "missing_app_id", "No App ID provided.")
+	case s.cfg.AppID != "" && appID != s.cfg.AppID:
+		abortWithError(c, http.StatusUnauthorized, "invalid_app_id", "Invalid App ID provided.")
+	}
+}
+
+// limit counts rate requests against the quota, like the real API usage.json is free.
+func (s *Server) limit(c *gin.Context) {
+	s.mu.Lock()
+	exceeded := s.cfg.RequestsQuota > 0 && s.requests >= s.cfg.RequestsQuota
+
+	if !exceeded {
+		s.requests++
+	}
+	s.mu.Unlock()
+
+	if exceeded {
+		abortWithError(c, http.StatusTooManyRequests, "access_restricted",
+			"Access restricted for repeated over-use (status: 429).")
+	}
+}
+
+func (s *Server) latest(c *gin.Context) {
+	s.mu.Lock()
+	rates := maps.Clone(s.rates)
+	timestamp := s.timestamp
+	s.mu.Unlock()
+
+	s.respondRates(c, rates, timestamp)
+}
+
+// historical derives a stable table for every past day from the current rates,
+// the same date always returns the same rates.
+func (s *Server) historical(c *gin.Context) {
+	date, err := time.Parse(dateLayout, strings.TrimSuffix(c.Param("date"), ".json"))
+	if err != nil || date.After(s.now()) {
+		abortWithError(c, http.StatusBadRequest, "invalid_date", "Invalid date provided.")
+
+		return
+	}
+
+	rng := rand.New(rand.NewPCG(s.cfg.Seed, uint64(date.Unix()))) //nolint:gosec // fake prices, not security
+
+	rates := make(map[string]decimal.Decimal, len(s.cfg.Rates))
+	for _, currency := range slices.Sorted(maps.Keys(s.cfg.Rates)) {
+		change := math.Exp(rng.NormFloat64() * historicalVolatility)
+		rates[currency] = roundRate(s.cfg.Rates[currency].Mul(decimal.NewFromFloat(change)))
+	}
+
+	rates[base] = decimal.NewFromInt(1)
+
+	s.respondRates(c, rates, date.Add(24*time.Hour-time.Second))
+}
+
+func (s *Server) respondRates(c *gin.Context, rates map[string]decimal.Decimal, timestamp time.Time) {
+	requestedBase := c.DefaultQuery("base", base)
+	if requestedBase != base {
+		if !s.cfg.AllowBase {
+			abortWithError(c, http.StatusForbidden, "not_allowed",
+				"Changing the API `base` currency is available for Developer, Enterprise and Unlimited plan clients.")
+
+			return
+		}
+
+		baseRate, ok := rates[requestedBase]
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, "invalid_base",
+				"Client requested rates for an unsupported base currency.")
+
+			return
+		}
+
+		for currency, rate := range rates {
+			rates[currency] = rate.DivRound(baseRate, ratePrecision)
+		}
+	}
+
+	if symbols := c.Query("symbols"); symbols != "" {
+		if !s.cfg.AllowSymbols {
+			abortWithError(c, http.StatusForbidden, "not_allowed",
+				"Requesting specific symbols is available for Developer, Enterprise and Unlimited plan clients.")
+
+			return
+		}
+
+		filtered := make(map[string]decimal.Decimal)
+
+		for _, currency := range strings.Split(symbols, ",") {
+			if rate, ok := rates[currency]; ok {
+				filtered[currency] = rate
+			}
+		}
+
+		rates = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"disclaimer": "Fake data for development only.",
+		"license":    "Fake data for development only.",
+		"timestamp":  timestamp.Unix(),
+		"base":       requestedBase,
+		"rates":      numbers(rates),
+	})
+}
+
+func (s *Server) usage(c *gin.Context) {
+	s.mu.Lock()
+	requests := s.requests
+	s.mu.Unlock()
+
+	now := s.now()
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	quota, remaining := -1, -1
+	if s.cfg.RequestsQuota > 0 {
+		quota = s.cfg.RequestsQuota
+		remaining = max(quota-requests, 0)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"data": gin.H{
+			"app_id": c.Query("app_id"),
+			"status": "active",
+			"plan": gin.H{
+				"name":             "Fake",
+				"quota":            fmt.Sprintf("%d requests / month", quota),
+				"update_frequency": s.cfg.WalkInterval.String(),
+				"features": gin.H{
+					"base":        s.cfg.AllowBase,
+					"symbols":     s.cfg.AllowSymbols,
+					"time-series": false,
+				},
+			},
+			"usage": gin.H{
+				"requests":           requests,
+				"requests_quota":     quota,
+				"requests_remaining": remaining,
+				"days_elapsed":       now.Day(),
+				"days_remaining":     daysInMonth - now.Day(),
+				"daily_average":      requests / now.Day(),
+			},
+		},
+	})
+}
+
+func (s *Server) currencies(c *gin.Context) {
+	c.JSON(http.StatusOK, s.cfg.Names)
+}
+
+func abortWithError(c *gin.Context, status int, message, description string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"error":       true,
+		"status":      status,
+		"message":     message,
+		"description": description,
+	})
+}
@@ -0,0 +1,187 @@
+package fakeoxr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"main/internal/api/openexchange"
+	"main/internal/errs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newClient(t *testing.T, cfg Config, appID string, opts ...openexchange.Option) (*Server, openexchange.OpenExchange) {
+	t.Helper()
+
+	server := New(cfg)
+
+	srv := httptest.NewServer(server.Handler())
+	t.Cleanup(srv.Close)
+
+	client, err := openexchange.New(srv.URL+"/api/", appID, opts...)
+	if err != nil {
+		t.Fatalf("openexchange.New() unexpected error: %v", err)
+	}
+
+	return server, client
+}
+
+func TestServer_OpenExchangeClient(t *testing.T) {
+	ctx := context.Background()
+
+	_, client := newClient(t, Config{AppID: "dev", AllowSymbols: true, Seed: 1}, "dev", openexchange.WithSymbols())
+
+	resp, err := client.GetCurrencyRates(ctx, []string{"EUR", "PLN"})
+	if err != nil {
+		t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+	}
+
+	if resp.Base != "USD" || !resp.Rates["EUR"].Equal(DefaultRates()["EUR"]) || len(resp.Rates) != 2 {
+		t.Errorf("GetCurrencyRates() got = %+v", resp)
+	}
+
+	_, err = client.GetCurrencyRatesForBase(ctx, "EUR", []string{"USD"})
+	if !errors.Is(err, errs.ErrNotAllowed) {
+		t.Errorf("GetCurrencyRatesForBase() error = %v, want %v", err, errs.ErrNotAllowed)
+	}
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	first, err := client.GetHistoricalCurrencyRates(ctx, day, []string{"EUR"})
+	if err != nil {
+		t.Fatalf("GetHistoricalCurrencyRates() unexpected error: %v", err)
+	}
+
+	second, err := client.GetHistoricalCurrencyRates(ctx, day, []string{"EUR"})
+	if err != nil || !first.Rates["EUR"].Equal(second.Rates["EUR"]) {
+		t.Errorf("GetHistoricalCurrencyRates() not stable: %v != %v, err %v", first.Rates, second.Rates, err)
+	}
+
+	_, err = client.GetHistoricalCurrencyRates(ctx, time.Now().AddDate(0, 0, 2), []string{"EUR"})
+	if !errors.Is(err, errs.ErrAPIResponse) {
+		t.Errorf("GetHistoricalCurrencyRates() future date error = %v, want %v", err, errs.ErrAPIResponse)
+	}
+
+	names, err := client.GetCurrencyNames(ctx)
+	if err != nil || names["EUR"] != "Euro" {
+		t.Errorf("GetCurrencyNames() got = %v, err %v", names, err)
+	}
+
+	_, wrongClient := newClient(t, Config{AppID: "dev"}, "other")
+
+	_, err = wrongClient.GetCurrencyRates(ctx, nil)
+	if !errors.Is(err, errs.ErrInvalidAppID) {
+		t.Errorf("GetCurrencyRates() with wrong app_id error = %v, want %v", err, errs.ErrInvalidAppID)
+	}
+}
+
+func TestServer_Base(t *testing.T) {
+	_, client := newClient(t, Config{AllowBase: true}, "dev")
+
+	resp, err := client.GetCurrencyRatesForBase(context.Background(), "EUR", []string{"EUR", "USD"})
+	if err != nil {
+		t.Fatalf("GetCurrencyRatesForBase() unexpected error: %v", err)
+	}
+
+	if resp.Base != "EUR" || resp.Rates["USD"].String() != "1.150568" || resp.Rates["EUR"].String() != "1" {
+		t.Errorf("GetCurrencyRatesForBase() got = %+v", resp)
+	}
+
+	_, err = client.GetCurrencyRatesForBase(context.Background(), "XYZ", nil)
+	if !errors.Is(err, errs.ErrInvalidBase) {
+		t.Errorf("GetCurrencyRatesForBase() error = %v, want %v", err, errs.ErrInvalidBase)
+	}
+}
+
+func TestServer_Quota(t *testing.T) {
+	ctx := context.Background()
+
+	_, client := newClient(t, Config{RequestsQuota: 2}, "dev")
+
+	for range 2 {
+		if _, err := client.GetCurrencyRates(ctx, nil); err != nil {
+			t.Fatalf("GetCurrencyRates() unexpected error: %v", err)
+		}
+	}
+
+	_, err := client.GetCurrencyRates(ctx, nil)
+	if !errors.Is(err, errs.ErrQuotaExceeded) {
+		t.Errorf("GetCurrencyRates() over quota error = %v, want %v", err, errs.ErrQuotaExceeded)
+	}
+
+	usage, err := client.GetUsage(ctx)
+	if err != nil {
+		t.Fatalf("GetUsage() unexpected error: %v", err)
+	}
+
+	if usage.RequestsQuota != 2 || usage.RequestsRemaining != 0 {
+		t.Errorf("GetUsage() got = %+v", usage)
+	}
+}
+
+func TestServer_InjectedErrors(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}).Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		kind       string
+		wantStatus int
+		wantJSON   bool
+	}{
+		{kind: ErrorUnauthorized, wantStatus: http.StatusUnauthorized, wantJSON: true},
+		{kind: ErrorRateLimit, wantStatus: http.StatusTooManyRequests, wantJSON: true},
+		{kind: ErrorServer, wantStatus: http.StatusInternalServerError},
+		{kind: ErrorUnavailable, wantStatus: http.StatusServiceUnavailable},
+		{kind: ErrorMalformed, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(
+				context.Background(), http.MethodGet, srv.URL+"/api/latest.json?app_id=dev", nil)
+			if err != nil {
+				t.Fatalf("NewRequest() error = %v", err)
+			}
+
+			req.Header.Set(ErrorHeader, tt.kind)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status got = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			var payload map[string]any
+
+			decodeErr := json.NewDecoder(resp.Body).Decode(&payload)
+			if tt.wantJSON != (decodeErr == nil) {
+				t.Errorf("decode error = %v, want JSON body %v", decodeErr, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestServer_Walk(t *testing.T) {
+	server := New(Config{Volatility: 0.01, Seed: 7})
+
+	server.walk()
+
+	if !server.rates["USD"].Equal(DefaultRates()["USD"]) {
+		t.Errorf("walk() moved the base rate to %v", server.rates["USD"])
+	}
+
+	if server.rates["EUR"].Equal(DefaultRates()["EUR"]) {
+		t.Errorf("walk() did not move EUR")
+	}
+
+	if !server.rates["BTC"].IsPositive() {
+		t.Errorf("walk() rounded BTC to %v", server.rates["BTC"])
+	}
+}
@@ -0,0 +1,91 @@
+package alerts
+
+import (
+	"fmt"
+	"main/internal/alerts"
+	"main/internal/errs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Manager interface {
+	Create(alert alerts.Alert) (alerts.Alert, error)
+	List() []alerts.Alert
+	Get(id string) (alerts.Alert, error)
+	Delete(id string) error
+	DeadLetters() []alerts.DeadLetter
+}
+
+type Handler struct {
+	manager      Manager
+	errorHandler errs.ErrorHandler
+}
+
+func NewHandler(
+	manager Manager,
+	errorHandler errs.ErrorHandler,
+) *Handler {
+	return &Handler{
+		manager:      manager,
+		errorHandler: errorHandler,
+	}
+}
+
+func (h *Handler) HandleCreate(c *gin.Context) {
+	var alert alerts.Alert
+
+	if err := c.ShouldBindJSON(&alert); err != nil {
+		h.errorHandler.Handle(c, fmt.Errorf("%w: %w", errs.ErrInvalidAlert, err))
+
+		return
+	}
+
+	created, err := h.manager.Create(alert)
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *Handler) HandleList(c *gin.Context) {
+	list := h.manager.List()
+	if list == nil {
+		list = []alerts.Alert{}
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) HandleGet(c *gin.Context) {
+	alert, err := h.manager.Get(c.Param("id"))
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+func (h *Handler) HandleDelete(c *gin.Context) {
+	if err := h.manager.Delete(c.Param("id")); err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) HandleDeadLetters(c *gin.Context) {
+	deadLetters := h.manager.DeadLetters()
+	if deadLetters == nil {
+		deadLetters = []alerts.DeadLetter{}
+	}
+
+	c.JSON(http.StatusOK, deadLetters)
+}
@@ -0,0 +1,156 @@
+package alerts
+
+import (
+	"encoding/json"
+	"main/internal/alerts"
+	"main/internal/api"
+	"main/internal/errs/currency"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MockUpdates struct{}
+
+func (MockUpdates) Subscribe() (<-chan api.Response, func()) {
+	return make(chan api.Response), func() {}
+}
+
+func newRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	engine, err := alerts.New(
+		MockUpdates{},
+		nil,
+		alerts.NewFileStore(filepath.Join(t.TempDir(), "alerts.json")),
+		alerts.NewNotifier([]byte("secret"), alerts.RetryPolicy{}, time.Second, alerts.NewTargetPolicy(nil)),
+		0,
+	)
+	if err != nil {
+		t.Fatalf("alerts.New() error = %v", err)
+	}
+
+	handler := NewHandler(engine, currency.NewErrorHandler())
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/alerts", handler.HandleCreate)
+	router.GET("/alerts", handler.HandleList)
+	router.GET("/alerts/dead-letters", handler.HandleDeadLetters)
+	router.GET("/alerts/:id", handler.HandleGet)
+	router.DELETE("/alerts/:id", handler.HandleDelete)
+
+	return router
+}
+
+func serve(router *gin.Engine, method, url, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+	return recorder
+}
+
+func TestHandler_HandleCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "crossing alert, status 201",
+			body:       `{"from":"EUR","to":"PLN","condition":"crosses","threshold":"4.30","webhookUrl":"https://example.com/hook"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "change alert with a number, status 201",
+			body:       `{"from":"WBTC","to":"USDT","condition":"change","percent":5,"window":"1h","webhookUrl":"https://example.com/hook"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "unknown condition, status 400",
+			body:       `{"from":"EUR","to":"PLN","condition":"above","threshold":"4.30","webhookUrl":"https://example.com/hook"}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "error invalid alert: condition must be crosses or change",
+		},
+		{
+			name:       "missing window, status 400",
+			body:       `{"from":"WBTC","to":"USDT","condition":"change","percent":5,"webhookUrl":"https://example.com/hook"}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "error invalid alert: window must be a positive duration like 1h",
+		},
+		{
+			name:       "metadata service webhook, status 400",
+			body:       `{"from":"EUR","to":"PLN","condition":"crosses","threshold":"4.30","webhookUrl":"http://169.254.169.254/latest"}`,
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "error invalid alert: webhookUrl must not point to a loopback, link-local or private address",
+		},
+		{
+			name:       "malformed body, status 400",
+			body:       `{"from":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serve(newRouter(t), http.MethodPost, "/alerts", tt.body)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("HandleCreate() status = %v, want %v, body %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			if tt.wantErr != "" {
+				var body map[string]string
+				_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+
+				if body["error"] != tt.wantErr {
+					t.Errorf("HandleCreate() error = %v, want %v", body["error"], tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_Lifecycle(t *testing.T) {
+	router := newRouter(t)
+
+	recorder := serve(router, http.MethodGet, "/alerts", "")
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "[]" {
+		t.Fatalf("HandleList() got = %v %s, want empty list", recorder.Code, recorder.Body)
+	}
+
+	recorder = serve(router, http.MethodPost, "/alerts",
+		`{"from":"EUR","to":"PLN","condition":"crosses","threshold":4.3,"webhookUrl":"https://example.com/hook"}`)
+
+	var created alerts.Alert
+	if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil || created.ID == "" {
+		t.Fatalf("HandleCreate() body = %s, error = %v", recorder.Body, err)
+	}
+
+	recorder = serve(router, http.MethodGet, "/alerts/"+created.ID, "")
+	if recorder.Code != http.StatusOK {
+		t.Errorf("HandleGet() status = %v, want %v", recorder.Code, http.StatusOK)
+	}
+
+	recorder = serve(router, http.MethodDelete, "/alerts/"+created.ID, "")
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("HandleDelete() status = %v, want %v", recorder.Code, http.StatusNoContent)
+	}
+
+	recorder = serve(router, http.MethodGet, "/alerts/"+created.ID, "")
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("HandleGet() status = %v, want %v", recorder.Code, http.StatusNotFound)
+	}
+
+	recorder = serve(router, http.MethodGet, "/alerts/dead-letters", "")
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "[]" {
+		t.Errorf("HandleDeadLetters() got = %v %s, want empty list", recorder.Code, recorder.Body)
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler lets through only the requests carrying "Authorization: Bearer <token>".
+type Handler struct {
+	token []byte
+}
+
+func NewHandler(token string) *Handler {
+	return &Handler{
+		token: []byte(token),
+	}
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(given), h.token) != 1 {
+		c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+
+		return
+	}
+
+	c.Next()
+}
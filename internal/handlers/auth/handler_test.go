@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{
+			name:       "missing header, status 401",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong scheme, status 401",
+			authorization: "Basic secret",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong token, status 401",
+			authorization: "Bearer guess",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "token prefix only, status 401",
+			authorization: "Bearer secre",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "correct token, status ok",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusOK,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/admin", NewHandler("secret").Handle, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("Handle() status = %v, want %v", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus != http.StatusUnauthorized {
+				return
+			}
+
+			if got := recorder.Header().Get("WWW-Authenticate"); got != `Bearer realm="admin"` {
+				t.Errorf("Handle() WWW-Authenticate = %q, want the bearer challenge", got)
+			}
+
+			if got := recorder.Body.String(); got != `{"error":"unauthorized"}` {
+				t.Errorf("Handle() body = %s, want the unauthorized error", got)
+			}
+		})
+	}
+}
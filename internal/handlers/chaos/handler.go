@@ -0,0 +1,46 @@
+package chaos
+
+import (
+	"main/internal/chaos"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Injector interface {
+	Settings() chaos.Settings
+	Update(settings chaos.Settings) error
+}
+
+type Handler struct {
+	injector Injector
+}
+
+func NewHandler(injector Injector) *Handler {
+	return &Handler{
+		injector: injector,
+	}
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	c.JSON(http.StatusOK, h.injector.Settings())
+}
+
+// HandleUpdate replaces the fault injection settings, the change applies to the next call.
+func (h *Handler) HandleUpdate(c *gin.Context) {
+	var settings chaos.Settings
+
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if err := h.injector.Update(settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, h.injector.Settings())
+}
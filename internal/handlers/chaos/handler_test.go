@@ -0,0 +1,100 @@
+package chaos
+
+import (
+	"encoding/json"
+	"main/internal/chaos"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	injector, err := chaos.NewInjector(chaos.Settings{Fraction: 0.2, LatencyMs: 4000, Faults: []string{chaos.FaultLatency}})
+	if err != nil {
+		t.Fatalf("chaos.NewInjector() error = %v", err)
+	}
+
+	handler := NewHandler(injector)
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/admin/chaos", handler.Handle)
+	router.PUT("/admin/chaos", handler.HandleUpdate)
+
+	return router
+}
+
+func TestHandler_HandleUpdate(t *testing.T) {
+	initial := chaos.Settings{Fraction: 0.2, LatencyMs: 4000, Faults: []string{chaos.FaultLatency}}
+
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantSettings chaos.Settings
+	}{
+		{
+			name:         "settings replaced, status ok",
+			body:         `{"enabled":true,"fraction":0.5,"latencyMs":100,"faults":["error","zero"]}`,
+			wantStatus:   http.StatusOK,
+			wantSettings: chaos.Settings{Enabled: true, Fraction: 0.5, LatencyMs: 100, Faults: []string{chaos.FaultError, chaos.FaultZero}},
+		},
+		{
+			name:         "switched off, status ok",
+			body:         `{"enabled":false,"fraction":0.2,"latencyMs":4000,"faults":["latency"]}`,
+			wantStatus:   http.StatusOK,
+			wantSettings: initial,
+		},
+		{
+			name:         "fraction out of range, status 400",
+			body:         `{"enabled":true,"fraction":1.5,"faults":["error"]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantSettings: initial,
+		},
+		{
+			name:         "unknown fault, status 400",
+			body:         `{"enabled":true,"fraction":0.5,"faults":["fire"]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantSettings: initial,
+		},
+		{
+			name:         "malformed body, status 400",
+			body:         `{"enabled":`,
+			wantStatus:   http.StatusBadRequest,
+			wantSettings: initial,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRouter(t)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin/chaos", strings.NewReader(tt.body)))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("HandleUpdate() status = %v, want %v, body %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			// The settings in force are read back, a rejected update leaves them untouched.
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/chaos", nil))
+
+			var got chaos.Settings
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantSettings) {
+				t.Errorf("Handle() got = %+v, want %+v", got, tt.wantSettings)
+			}
+		})
+	}
+}
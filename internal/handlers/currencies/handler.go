@@ -0,0 +1,173 @@
+package currencies
+
+import (
+	"context"
+	"log/slog"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/repository"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	typeFiat   = "fiat"
+	typeCrypto = "crypto"
+
+	endpointRates    = "rates"
+	endpointExchange = "exchange"
+
+	// namesTTL is how long upstream currency names are kept, they almost never change.
+	namesTTL = 24 * time.Hour
+	// namesTimeout bounds the shared fetch, namesRetryDelay keeps a failing upstream from being asked on every request.
+	namesTimeout    = 10 * time.Second
+	namesRetryDelay = time.Minute
+)
+
+type Response struct {
+	Code             string   `json:"code"`
+	Name             string   `json:"name,omitempty"`
+	Type             string   `json:"type"`
+	DecimalPrecision int      `json:"decimalPrecision"`
+	Endpoints        []string `json:"endpoints"`
+}
+
+type Handler struct {
+	currencyNames api.CurrencyNames
+	tokens        repository.CurrencyList
+	errorHandler  errs.ErrorHandler
+	now           func() time.Time
+
+	group     singleflight.Group
+	mu        sync.Mutex
+	names     map[string]string
+	fetchedAt time.Time
+	failedAt  time.Time
+}
+
+// NewHandler accepts nil currencyNames when no provider publishes currency names,
+// only the tokens supported by /exchange are listed then.
+func NewHandler(
+	currencyNames api.CurrencyNames,
+	tokens repository.CurrencyList,
+	errorHandler errs.ErrorHandler,
+) *Handler {
+	return &Handler{
+		currencyNames: currencyNames,
+		tokens:        tokens,
+		errorHandler:  errorHandler,
+		now:           time.Now,
+	}
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := ctx.Err(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is shutting down"})
+
+		return
+	}
+
+	responses, err := h.listCurrencies(ctx)
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func (h *Handler) listCurrencies(ctx context.Context) ([]Response, error) {
+	names := h.upstreamNames(ctx)
+
+	currencies := make(map[string]Response, len(names))
+
+	for code, name := range names {
+		currencies[code] = Response{
+			Code:             code,
+			Name:             name,
+			Type:             typeFiat,
+			DecimalPrecision: minorUnits(code),
+			Endpoints:        []string{endpointRates},
+		}
+	}
+
+	// The repository holds the tokens, whatever the upstream calls them.
+	for code, details := range h.tokens.List() {
+		currency, ok := currencies[code]
+		if !ok {
+			currency = Response{Code: code}
+		}
+
+		currency.Type = typeCrypto
+		currency.DecimalPrecision = details.DecimalPrecision
+		currency.Endpoints = append(currency.Endpoints, endpointExchange)
+
+		currencies[code] = currency
+	}
+
+	responses := make([]Response, 0, len(currencies))
+	for _, currency := range currencies {
+		responses = append(responses, currency)
+	}
+
+	slices.SortFunc(responses, func(a, b Response) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return responses, nil
+}
+
+// upstreamNames falls back to the last names fetched, or none, when the upstream fails,
+// the tokens are listed either way.
+func (h *Handler) upstreamNames(ctx context.Context) map[string]string {
+	if h.currencyNames == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	names, fetchedAt, failedAt := h.names, h.fetchedAt, h.failedAt
+	h.mu.Unlock()
+
+	now := h.now()
+	if (names != nil && now.Sub(fetchedAt) < namesTTL) || now.Sub(failedAt) < namesRetryDelay {
+		return names
+	}
+
+	// Concurrent requests share one fetch, which outlives the request that started it.
+	fetched, err, _ := h.group.Do("names", func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), namesTimeout)
+		defer cancel()
+
+		return h.currencyNames.GetCurrencyNames(fetchCtx)
+	})
+	if err != nil {
+		slog.Warn("Failed to get currency names", slog.String("error", err.Error()))
+
+		h.mu.Lock()
+		h.failedAt = h.now()
+		h.mu.Unlock()
+
+		return names
+	}
+
+	names, ok := fetched.(map[string]string)
+	if !ok {
+		return nil
+	}
+
+	h.mu.Lock()
+	h.names = names
+	h.fetchedAt = h.now()
+	h.mu.Unlock()
+
+	return names
+}
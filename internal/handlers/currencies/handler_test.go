@@ -0,0 +1,211 @@
+package currencies
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/errs/currency"
+	"main/internal/repository/memory"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MockCurrencyNames struct {
+	names map[string]string
+	err   error
+	calls int
+}
+
+func (m *MockCurrencyNames) GetCurrencyNames(_ context.Context) (map[string]string, error) {
+	m.calls++
+
+	return m.names, m.err
+}
+
+func TestHandler_Handle(t *testing.T) {
+	names := map[string]string{
+		"EUR":  "Euro",
+		"JPY":  "Japanese Yen",
+		"USD":  "United States Dollar",
+		"USDT": "Tether",
+	}
+
+	tests := []struct {
+		name          string
+		currencyNames api.CurrencyNames
+		wantStatus    int
+		wantErr       string
+		wantBody      []Response
+	}{
+		{
+			name:          "upstream names merged with tokens, status ok",
+			currencyNames: &MockCurrencyNames{names: names},
+			wantStatus:    http.StatusOK,
+			wantBody: []Response{
+				{Code: "BEER", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "EUR", Name: "Euro", Type: "fiat", DecimalPrecision: 2, Endpoints: []string{"rates"}},
+				{Code: "FLOKI", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "GATE", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "JPY", Name: "Japanese Yen", Type: "fiat", DecimalPrecision: 0, Endpoints: []string{"rates"}},
+				{Code: "USD", Name: "United States Dollar", Type: "fiat", DecimalPrecision: 2, Endpoints: []string{"rates"}},
+				{Code: "USDT", Name: "Tether", Type: "crypto", DecimalPrecision: 6, Endpoints: []string{"rates", "exchange"}},
+				{Code: "WBTC", Type: "crypto", DecimalPrecision: 8, Endpoints: []string{"exchange"}},
+			},
+		},
+		{
+			name:       "no provider with names lists tokens only, status ok",
+			wantStatus: http.StatusOK,
+			wantBody: []Response{
+				{Code: "BEER", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "FLOKI", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "GATE", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "USDT", Type: "crypto", DecimalPrecision: 6, Endpoints: []string{"exchange"}},
+				{Code: "WBTC", Type: "crypto", DecimalPrecision: 8, Endpoints: []string{"exchange"}},
+			},
+		},
+		{
+			name:          "upstream error lists tokens only, status ok",
+			currencyNames: &MockCurrencyNames{err: errs.ErrQuotaExceeded},
+			wantStatus:    http.StatusOK,
+			wantBody: []Response{
+				{Code: "BEER", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "FLOKI", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "GATE", Type: "crypto", DecimalPrecision: 18, Endpoints: []string{"exchange"}},
+				{Code: "USDT", Type: "crypto", DecimalPrecision: 6, Endpoints: []string{"exchange"}},
+				{Code: "WBTC", Type: "crypto", DecimalPrecision: 8, Endpoints: []string{"exchange"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(recorder)
+
+			c.Request = httptest.NewRequestWithContext(
+				context.Background(), "GET", "/currencies", nil)
+
+			handler := NewHandler(tt.currencyNames, memory.NewCurrencyRateRepo(), currency.NewErrorHandler())
+			handler.Handle(c)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %d want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantErr != "" {
+				var response map[string]string
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+					t.Fatalf("invalid error response: %v", err)
+				}
+
+				if !strings.Contains(response["error"], tt.wantErr) {
+					t.Errorf("handler returned unexpected error: got %q want %q", response["error"], tt.wantErr)
+				}
+
+				return
+			}
+
+			var got []Response
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("listCurrencies() got = %+v, want %+v", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_NamesCached(t *testing.T) {
+	names := &MockCurrencyNames{names: map[string]string{"USD": "United States Dollar"}}
+
+	handler := NewHandler(names, memory.NewCurrencyRateRepo(), currency.NewErrorHandler())
+
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := handler.listCurrencies(context.Background()); err != nil {
+			t.Fatalf("listCurrencies() error = %v", err)
+		}
+	}
+
+	if names.calls != 1 {
+		t.Errorf("GetCurrencyNames() calls = %d, want 1", names.calls)
+	}
+
+	now = now.Add(namesTTL)
+
+	if _, err := handler.listCurrencies(context.Background()); err != nil {
+		t.Fatalf("listCurrencies() error = %v", err)
+	}
+
+	if names.calls != 2 {
+		t.Errorf("GetCurrencyNames() calls after TTL = %d, want 2", names.calls)
+	}
+}
+
+func TestHandler_NamesKeptOnFailure(t *testing.T) {
+	names := &MockCurrencyNames{names: map[string]string{"USD": "United States Dollar"}}
+
+	handler := NewHandler(names, memory.NewCurrencyRateRepo(), currency.NewErrorHandler())
+
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+
+	if _, err := handler.listCurrencies(context.Background()); err != nil {
+		t.Fatalf("listCurrencies() error = %v", err)
+	}
+
+	now = now.Add(namesTTL)
+	names.names, names.err = nil, errors.New("random error")
+
+	got, err := handler.listCurrencies(context.Background())
+	if err != nil {
+		t.Fatalf("listCurrencies() error = %v", err)
+	}
+
+	if !slices.ContainsFunc(got, func(r Response) bool { return r.Code == "USD" }) {
+		t.Errorf("listCurrencies() got = %+v, want the last fetched names kept", got)
+	}
+}
+
+func TestHandler_NamesFailureBacksOff(t *testing.T) {
+	names := &MockCurrencyNames{err: errors.New("random error")}
+
+	handler := NewHandler(names, memory.NewCurrencyRateRepo(), currency.NewErrorHandler())
+
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := handler.listCurrencies(context.Background()); err != nil {
+			t.Fatalf("listCurrencies() error = %v", err)
+		}
+	}
+
+	if names.calls != 1 {
+		t.Errorf("GetCurrencyNames() calls = %d, want 1", names.calls)
+	}
+
+	now = now.Add(namesRetryDelay)
+
+	if _, err := handler.listCurrencies(context.Background()); err != nil {
+		t.Fatalf("listCurrencies() error = %v", err)
+	}
+
+	if names.calls != 2 {
+		t.Errorf("GetCurrencyNames() calls after the retry delay = %d, want 2", names.calls)
+	}
+}
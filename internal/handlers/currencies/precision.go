@@ -0,0 +1,25 @@
+package currencies
+
+// defaultMinorUnits is the number of decimal places of most currencies, e.g. USD cents.
+const defaultMinorUnits = 2
+
+// exceptionalMinorUnits lists the ISO 4217 currencies without two decimal places,
+// and the codes openexchangerates quotes beyond the standard. Precious metals and XDR
+// have no minor unit.
+var exceptionalMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"XAG": 0, "XAU": 0, "XPD": 0, "XPT": 0, "XDR": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+	"BTC": 8,
+}
+
+// minorUnits returns the number of decimal places amounts in the currency are expressed with.
+func minorUnits(code string) int {
+	if units, ok := exceptionalMinorUnits[code]; ok {
+		return units
+	}
+
+	return defaultMinorUnits
+}
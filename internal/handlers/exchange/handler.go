@@ -66,16 +66,25 @@ func (h *Handler) exchange(c *gin.Context) (Response, error) {
 		return Response{}, errs.ErrAmountNotNumber
 	}
 
-	if amount.LessThan(decimal.NewFromFloat(0)) {
+	return Quote(h.currencyRateRepo, sourceCurrency, targetCurrency, amount)
+}
+
+// Quote converts amount of the source currency into the target one at the repository rates.
+func Quote(
+	currencyRateRepo repository.CurrencyRate,
+	sourceCurrency, targetCurrency string,
+	amount decimal.Decimal,
+) (Response, error) {
+	if amount.IsNegative() {
 		return Response{}, errs.ErrNegativeAmount
 	}
 
-	sourceCurrencyDetails, err := h.currencyRateRepo.Get(sourceCurrency)
+	sourceCurrencyDetails, err := currencyRateRepo.Get(sourceCurrency)
 	if err != nil {
 		return Response{}, fmt.Errorf("failed to get source currency rate: %w", err)
 	}
 
-	targetCurrencyDetails, err := h.currencyRateRepo.Get(targetCurrency)
+	targetCurrencyDetails, err := currencyRateRepo.Get(targetCurrency)
 	if err != nil {
 		return Response{}, fmt.Errorf("failed to get the target currency rate: %w", err)
 	}
@@ -101,19 +110,14 @@ func calculateExchange(
 	targetCurrencyDetails memory.CurrencyDetails,
 	amount decimal.Decimal,
 ) (string, error) {
-	sourceRate := decimal.NewFromFloat(sourceCurrencyDetails.Rate)
-
-	targetRate := decimal.NewFromFloat(targetCurrencyDetails.Rate)
-
-	exchangeRate := sourceRate.Div(targetRate)
-
-	result := amount.Mul(exchangeRate)
-
 	decimalPlaces := int32(targetCurrencyDetails.DecimalPrecision)
 
+	// Dividing last keeps the only rounding at the target precision.
+	result := amount.Mul(sourceCurrencyDetails.Rate).DivRound(targetCurrencyDetails.Rate, decimalPlaces)
+
 	return result.StringFixed(decimalPlaces), nil
 }
 
 func zeroValue(source, target memory.CurrencyDetails) bool {
-	return source.Rate == 0 || target.Rate == 0 || source.DecimalPrecision == 0 || target.DecimalPrecision == 0
+	return source.Rate.IsZero() || target.Rate.IsZero() || source.DecimalPrecision == 0 || target.DecimalPrecision == 0
 }
@@ -15,6 +15,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -30,8 +31,8 @@ type MockWrongCurrencyRateRepo struct {
 func NewMockWrongStorageCurrencyRateRepo() *MockWrongCurrencyRateRepo {
 	return &MockWrongCurrencyRateRepo{
 		Storage: []memory.CurrencyDetails{
-			beer: {0, 0},
-			gate: {0, 0},
+			beer: {DecimalPrecision: 0, Rate: decimal.Zero},
+			gate: {DecimalPrecision: 0, Rate: decimal.Zero},
 		},
 	}
 }
@@ -64,7 +65,7 @@ func TestHandler_Handle(t *testing.T) {
 			errorHandler:     currency.NewErrorHandler(),
 			url:              "/exchange?from=GATE&to=FLOKI&amount=123.12345",
 			wantStatus:       http.StatusOK,
-			wantBody:         []byte(`{"from":"GATE","to":"FLOKI","amount":5923376.060924369747894400}`),
+			wantBody:         []byte(`{"from":"GATE","to":"FLOKI","amount":5923376.060924369747899160}`),
 			decimalPrecision: 18,
 		},
 		{
@@ -82,7 +83,7 @@ func TestHandler_Handle(t *testing.T) {
 			errorHandler:     currency.NewErrorHandler(),
 			url:              "/exchange?from=USDT&to=BEER&amount=1.0",
 			wantStatus:       http.StatusOK,
-			wantBody:         []byte(`{"from":"USDT","to":"BEER","amount":40593.254774481917919500}`),
+			wantBody:         []byte(`{"from":"USDT","to":"BEER","amount":40593.254774481917919545}`),
 			decimalPrecision: 18,
 		},
 		{
@@ -100,7 +101,16 @@ func TestHandler_Handle(t *testing.T) {
 			errorHandler:     currency.NewErrorHandler(),
 			url:              "/exchange?from=FLOKI&to=GATE&amount=50",
 			wantStatus:       http.StatusOK,
-			wantBody:         []byte(`{"from":"FLOKI","to":"GATE","amount":0.001039301310045000}`),
+			wantBody:         []byte(`{"from":"FLOKI","to":"GATE","amount":0.001039301310043668}`),
+			decimalPrecision: 18,
+		},
+		{
+			name:             "Test Exchange WBTC to BEER amount beyond float64 precision",
+			currencyRateRepo: memory.NewCurrencyRateRepo(),
+			errorHandler:     currency.NewErrorHandler(),
+			url:              "/exchange?from=WBTC&to=BEER&amount=123456789.123456789",
+			wantStatus:       http.StatusOK,
+			wantBody:         []byte(`{"from":"WBTC","to":"BEER","amount":286128892390419018.069344981714750102}`),
 			decimalPrecision: 18,
 		},
 		{
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"main/internal/api/breaker"
+	"main/internal/api/cache"
+	"main/internal/api/poller"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	statusOK          = "ok"
+	statusDegraded    = "degraded"
+	statusUnavailable = "unavailable"
+)
+
+type CircuitBreaker interface {
+	CircuitBreaker() (breaker.Snapshot, bool)
+}
+
+type Poller interface {
+	Status() poller.Status
+}
+
+type Cache interface {
+	Stats() cache.Stats
+}
+
+type Response struct {
+	Status    string                      `json:"status"`
+	Providers map[string]breaker.Snapshot `json:"providers"`
+	Poller    *poller.Status              `json:"poller,omitempty"`
+	Caches    map[string]cache.Stats      `json:"caches,omitempty"`
+}
+
+type Handler struct {
+	providers     map[string]CircuitBreaker
+	providerCount int
+	poller        Poller
+	caches        map[string]Cache
+}
+
+// NewHandler takes the breakers of the providers having one, out of providerCount in the chain.
+// It accepts a nil poller when rates are not refreshed in the background.
+func NewHandler(
+	providers map[string]CircuitBreaker,
+	providerCount int,
+	ratePoller Poller,
+	caches map[string]Cache,
+) *Handler {
+	return &Handler{
+		providers:     providers,
+		providerCount: providerCount,
+		poller:        ratePoller,
+		caches:        caches,
+	}
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	resp := h.check()
+
+	status := http.StatusOK
+	if resp.Status == statusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, resp)
+}
+
+func (h *Handler) check() Response {
+	snapshots := make(map[string]breaker.Snapshot, len(h.providers))
+	open := 0
+
+	for name, provider := range h.providers {
+		snapshot, ok := provider.CircuitBreaker()
+		if !ok {
+			continue
+		}
+
+		if snapshot.State == breaker.Open {
+			open++
+		}
+
+		snapshots[name] = snapshot
+	}
+
+	resp := Response{
+		Status:    statusOK,
+		Providers: snapshots,
+	}
+
+	if len(h.caches) > 0 {
+		resp.Caches = make(map[string]cache.Stats, len(h.caches))
+
+		for name, c := range h.caches {
+			resp.Caches[name] = c.Stats()
+		}
+	}
+
+	if h.poller != nil {
+		pollerStatus := h.poller.Status()
+		resp.Poller = &pollerStatus
+
+		if pollerStatus.ConsecutiveFailures > 0 {
+			resp.Status = statusDegraded
+		}
+	}
+
+	// Providers without a breaker are assumed to serve, so the service is unavailable only when all are open.
+	switch {
+	case open > 0 && open >= h.providerCount:
+		resp.Status = statusUnavailable
+	case open > 0:
+		resp.Status = statusDegraded
+	}
+
+	return resp
+}
@@ -0,0 +1,76 @@
+package health
+
+import (
+	"main/internal/api/breaker"
+	"main/internal/api/cache"
+	"testing"
+	"time"
+)
+
+type MockCircuitBreaker struct {
+	breaker *breaker.Breaker
+}
+
+func (m MockCircuitBreaker) CircuitBreaker() (breaker.Snapshot, bool) {
+	return m.breaker.Snapshot(), true
+}
+
+func openBreaker() MockCircuitBreaker {
+	b := breaker.New(1, time.Hour)
+	b.Failure()
+
+	return MockCircuitBreaker{breaker: b}
+}
+
+func TestHandler_Check(t *testing.T) {
+	tests := []struct {
+		name          string
+		providers     map[string]CircuitBreaker
+		providerCount int
+		want          string
+	}{
+		{
+			name:          "closed breaker",
+			providers:     map[string]CircuitBreaker{"oxr": MockCircuitBreaker{breaker: breaker.New(1, time.Hour)}},
+			providerCount: 1,
+			want:          statusOK,
+		},
+		{
+			name:          "open breaker with a provider without one",
+			providers:     map[string]CircuitBreaker{"oxr": openBreaker()},
+			providerCount: 2,
+			want:          statusDegraded,
+		},
+		{
+			name:          "every provider open",
+			providers:     map[string]CircuitBreaker{"oxr": openBreaker(), "backup": openBreaker()},
+			providerCount: 2,
+			want:          statusUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewHandler(tt.providers, tt.providerCount, nil, nil).check().Status; got != tt.want {
+				t.Errorf("check() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type MockCache struct {
+	stats cache.Stats
+}
+
+func (m MockCache) Stats() cache.Stats {
+	return m.stats
+}
+
+func TestHandler_CheckCaches(t *testing.T) {
+	caches := map[string]Cache{"rates": MockCache{stats: cache.Stats{Hits: 3, Misses: 1}}}
+
+	got := NewHandler(nil, 1, nil, caches).check().Caches
+	if got["rates"] != (cache.Stats{Hits: 3, Misses: 1}) {
+		t.Errorf("check() caches got = %+v, want the rates cache stats", got)
+	}
+}
@@ -0,0 +1,93 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/handlers/rates"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	dateLayout = "2006-01-02"
+)
+
+type Handler struct {
+	currencyRateAPI api.HistoricalCurrencyRate
+	errorHandler    errs.ErrorHandler
+	now             func() time.Time
+}
+
+func NewHandler(
+	currencyRateAPI api.HistoricalCurrencyRate,
+	errorHandler errs.ErrorHandler,
+) *Handler {
+	return &Handler{
+		currencyRateAPI: currencyRateAPI,
+		errorHandler:    errorHandler,
+		now:             time.Now,
+	}
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := ctx.Err(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is shutting down"})
+
+		return
+	}
+
+	responses, err := h.countHistoricalRates(ctx, c)
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func (h *Handler) countHistoricalRates(
+	ctx context.Context,
+	c *gin.Context,
+) ([]rates.Response, error) {
+	date, err := ParseDate(c.Query("date"), h.now())
+	if err != nil {
+		return nil, err
+	}
+
+	currencies, err := rates.ParseCurrencies(c.Query("currencies"))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.currencyRateAPI.GetHistoricalCurrencyRates(ctx, date, currencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical currency rates: %w", err)
+	}
+
+	return rates.Calculate(resp.Rates, currencies)
+}
+
+// ParseDate accepts days from the past and today, the only ones with published rates.
+func ParseDate(param string, now time.Time) (time.Time, error) {
+	if param == "" {
+		return time.Time{}, errs.ErrEmptyParam
+	}
+
+	date, err := time.Parse(dateLayout, param)
+	if err != nil {
+		return time.Time{}, errs.ErrInvalidDate
+	}
+
+	if date.After(now.UTC()) {
+		return time.Time{}, errs.ErrInvalidDate
+	}
+
+	return date, nil
+}
@@ -0,0 +1,148 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/errs/currency"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type MockHistoricalAPI struct {
+	err error
+}
+
+func (m MockHistoricalAPI) GetHistoricalCurrencyRates(
+	_ context.Context, date time.Time, currencies []string,
+) (api.Response, error) {
+	if m.err != nil {
+		return api.Response{}, m.err
+	}
+
+	history := map[string]map[string]decimal.Decimal{
+		"2024-01-02": {"USD": decimal.RequireFromString("1"), "EUR": decimal.RequireFromString("0.9"), "GBP": decimal.RequireFromString("0.8")},
+		"2025-06-18": {"USD": decimal.RequireFromString("1"), "EUR": decimal.RequireFromString("0.869136"), "GBP": decimal.RequireFromString("0.743653")},
+	}
+
+	rates, ok := history[date.Format(dateLayout)]
+	if !ok {
+		return api.Response{}, errs.ErrAPIResponse
+	}
+
+	resp := api.Response{
+		Base:      "USD",
+		Rates:     rates,
+		Timestamp: int(date.Unix()),
+	}
+
+	return resp.Filter(currencies)
+}
+
+func TestHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name       string
+		api        api.HistoricalCurrencyRate
+		url        string
+		wantStatus int
+		wantErr    string
+		wantBody   []byte
+	}{
+		{
+			name:       "rates for past date, status ok",
+			api:        MockHistoricalAPI{},
+			url:        "/rates/history?date=2024-01-02&currencies=USD,EUR",
+			wantStatus: http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"USD","to":"EUR","rate":0.90000000},{"from":"EUR","to":"USD","rate":1.11111111}]`,
+			),
+		},
+		{
+			name:       "rates for other past date, status ok",
+			api:        MockHistoricalAPI{},
+			url:        "/rates/history?date=2025-06-18&currencies=USD,GBP",
+			wantStatus: http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"USD","to":"GBP","rate":0.74365300},{"from":"GBP","to":"USD","rate":1.34471319}]`,
+			),
+		},
+		{
+			name:       "missing date, status 400",
+			api:        MockHistoricalAPI{},
+			url:        "/rates/history?currencies=USD,EUR",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed date, status 400",
+			api:        MockHistoricalAPI{},
+			url:        "/rates/history?date=02-01-2024&currencies=USD,EUR",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "future date, status 400",
+			api:        MockHistoricalAPI{},
+			url:        "/rates/history?date=2999-01-01&currencies=USD,EUR",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "single currency, status 400",
+			api:        MockHistoricalAPI{},
+			url:        "/rates/history?date=2024-01-02&currencies=USD",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown currency, status 404",
+			api:        MockHistoricalAPI{},
+			url:        "/rates/history?date=2024-01-02&currencies=USD,AAA",
+			wantStatus: http.StatusNotFound,
+			wantErr:    "error unknown currency",
+		},
+		{
+			name:       "provider without history, status 501",
+			api:        MockHistoricalAPI{err: errs.ErrHistoryNotSupported},
+			url:        "/rates/history?date=2024-01-02&currencies=USD,EUR",
+			wantStatus: http.StatusNotImplemented,
+			wantErr:    errs.ErrHistoryNotSupported.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(recorder)
+
+			c.Request = httptest.NewRequestWithContext(
+				context.Background(), "GET", tt.url, nil)
+
+			handler := NewHandler(tt.api, currency.NewErrorHandler())
+			handler.Handle(c)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %d want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantErr != "" {
+				var response map[string]string
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+					t.Fatalf("invalid error response: %v", err)
+				}
+
+				if response["error"] != tt.wantErr {
+					t.Errorf("handler returned unexpected error: got %q want %q", response["error"], tt.wantErr)
+				}
+			} else if tt.wantBody != nil {
+				if !reflect.DeepEqual(recorder.Body.Bytes(), tt.wantBody) {
+					t.Errorf("handler returned body = %s, want %s", recorder.Body.Bytes(), tt.wantBody)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/api"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const closeTimeout = time.Second
+
+type connection struct {
+	ws *websocket.Conn
+	h  *Handler
+
+	// mu guards the subscriptions and the rate table they are priced from.
+	mu            sync.Mutex
+	subscriptions map[string]subscription
+	sent          map[string]Message
+	table         *api.Response
+
+	// outMu guards the outbox, replies queue up, quotes replace the unsent one.
+	outMu   sync.Mutex
+	replies []Message
+	quotes  map[string]Message
+	order   []string
+	wake    chan struct{}
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newConnection(ws *websocket.Conn, h *Handler) *connection {
+	return &connection{
+		ws:            ws,
+		h:             h,
+		subscriptions: make(map[string]subscription),
+		sent:          make(map[string]Message),
+		quotes:        make(map[string]Message),
+		wake:          make(chan struct{}, 1),
+		closed:        make(chan struct{}),
+	}
+}
+
+func (c *connection) serve() {
+	updates, cancel := c.h.updates.Subscribe()
+	defer cancel()
+
+	go c.writeLoop()
+	go c.readLoop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-c.h.shutdown:
+			c.close(websocket.CloseGoingAway, "service is shutting down")
+
+			return
+		case table := <-updates:
+			c.refresh(table)
+		}
+	}
+}
+
+func (c *connection) readLoop() {
+	pongWait := c.h.limits.PingInterval + c.h.limits.WriteTimeout
+
+	c.ws.SetReadLimit(maxRequestSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var req Request
+
+		err := c.ws.ReadJSON(&req)
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				c.reply(Message{Type: typeError, Error: "invalid request: " + err.Error()})
+
+				continue
+			}
+
+			c.close(websocket.CloseNormalClosure, "")
+
+			return
+		}
+
+		c.handle(req)
+	}
+}
+
+func (c *connection) handle(req Request) {
+	switch req.Action {
+	case actionSubscribe:
+		c.subscribe(req)
+	case actionUnsubscribe:
+		c.unsubscribe(req)
+	default:
+		c.reply(Message{Type: typeError, Error: fmt.Sprintf("unknown action %q", req.Action)})
+	}
+}
+
+func (c *connection) subscribe(req Request) {
+	sub, err := parseSubscription(req)
+	if err != nil {
+		c.reply(Message{Type: typeError, Error: err.Error()})
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subscriptions[sub.id]; !ok && len(c.subscriptions) >= c.h.limits.MaxSubscriptions {
+		c.reply(Message{
+			Type:         typeError,
+			Subscription: sub.id,
+			Error:        fmt.Sprintf("subscription limit of %d reached", c.h.limits.MaxSubscriptions),
+		})
+
+		return
+	}
+
+	table, err := c.currentTable()
+	if err != nil {
+		c.reply(Message{Type: typeError, Subscription: sub.id, Error: err.Error()})
+
+		return
+	}
+
+	msg, err := quote(sub, table, c.h.currencyRateRepo)
+	if err != nil {
+		c.reply(Message{Type: typeError, Subscription: sub.id, Error: err.Error()})
+
+		return
+	}
+
+	c.subscriptions[sub.id] = sub
+	c.sent[sub.id] = msg
+
+	c.reply(Message{Type: typeSubscribed, Subscription: sub.id})
+	c.push(msg)
+}
+
+func (c *connection) unsubscribe(req Request) {
+	sub, err := parseSubscription(req)
+	if err != nil {
+		c.reply(Message{Type: typeError, Error: err.Error()})
+
+		return
+	}
+
+	c.mu.Lock()
+	_, ok := c.subscriptions[sub.id]
+	delete(c.subscriptions, sub.id)
+	delete(c.sent, sub.id)
+	c.mu.Unlock()
+
+	if !ok {
+		c.reply(Message{Type: typeError, Subscription: sub.id, Error: "not subscribed"})
+
+		return
+	}
+
+	c.outMu.Lock()
+	delete(c.quotes, sub.id)
+	c.outMu.Unlock()
+
+	c.reply(Message{Type: typeUnsubscribed, Subscription: sub.id})
+}
+
+// currentTable returns the last rate table, fetching it before the first update arrives.
+// The caller holds mu.
+func (c *connection) currentTable() (api.Response, error) {
+	if c.table != nil {
+		return *c.table, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.h.limits.WriteTimeout)
+	defer cancel()
+
+	table, err := c.h.updates.GetCurrencyRates(ctx, nil)
+	if err != nil {
+		return api.Response{}, err
+	}
+
+	c.table = &table
+
+	return table, nil
+}
+
+// refresh re-prices every subscription and pushes the quotes that changed.
+func (c *connection) refresh(table api.Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.table = &table
+
+	for id, sub := range c.subscriptions {
+		msg, err := quote(sub, table, c.h.currencyRateRepo)
+		if err != nil {
+			msg = Message{Type: typeError, Subscription: id, Error: err.Error()}
+		}
+
+		if c.sent[id] == msg {
+			continue
+		}
+
+		c.sent[id] = msg
+		c.push(msg)
+	}
+}
+
+// reply queues a message that must not be lost, a client not reading them is disconnected.
+func (c *connection) reply(msg Message) {
+	c.outMu.Lock()
+	overflow := len(c.replies) >= c.h.limits.MaxPending
+
+	if !overflow {
+		c.replies = append(c.replies, msg)
+	}
+	c.outMu.Unlock()
+
+	if overflow {
+		slog.Warn("Disconnecting slow websocket client", slog.String("remote", c.ws.RemoteAddr().String()))
+		c.close(websocket.ClosePolicyViolation, "too many pending messages")
+
+		return
+	}
+
+	c.signal()
+}
+
+// push queues a quote, replacing the previous one of the subscription when not sent yet.
+func (c *connection) push(msg Message) {
+	c.outMu.Lock()
+	if _, ok := c.quotes[msg.Subscription]; !ok {
+		c.order = append(c.order, msg.Subscription)
+	}
+
+	c.quotes[msg.Subscription] = msg
+	c.outMu.Unlock()
+
+	c.signal()
+}
+
+func (c *connection) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *connection) writeLoop() {
+	ping := time.NewTicker(c.h.limits.PingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ping.C:
+			err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.h.limits.WriteTimeout))
+			if err != nil {
+				c.close(websocket.CloseGoingAway, "")
+
+				return
+			}
+		case <-c.wake:
+			for _, msg := range c.drain() {
+				_ = c.ws.SetWriteDeadline(time.Now().Add(c.h.limits.WriteTimeout))
+
+				if err := c.ws.WriteJSON(msg); err != nil {
+					c.close(websocket.CloseGoingAway, "")
+
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *connection) drain() []Message {
+	c.outMu.Lock()
+	defer c.outMu.Unlock()
+
+	batch := c.replies
+	c.replies = nil
+
+	for _, id := range c.order {
+		if msg, ok := c.quotes[id]; ok {
+			batch = append(batch, msg)
+		}
+	}
+
+	c.order = nil
+	clear(c.quotes)
+
+	return batch
+}
+
+func (c *connection) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		_ = c.ws.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(code, text),
+			time.Now().Add(closeTimeout),
+		)
+
+		c.ws.Close()
+		close(c.closed)
+	})
+}
@@ -0,0 +1,125 @@
+package live
+
+import (
+	"main/internal/api"
+	"main/internal/repository"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultMaxSubscriptions = 20
+	defaultMaxPending       = 64
+	defaultWriteTimeout     = 10 * time.Second
+	defaultPingInterval     = 30 * time.Second
+
+	maxRequestSize = 4096
+)
+
+// Updates serves the current rate table and notifies about every change of it.
+type Updates interface {
+	api.CurrencyRate
+	Subscribe() (<-chan api.Response, func())
+}
+
+type Limits struct {
+	// MaxSubscriptions is the number of subscriptions a single connection may hold.
+	MaxSubscriptions int
+	// MaxPending is the number of replies queued for a client not reading them,
+	// it is disconnected above that. Quotes do not queue up, only the latest one is kept.
+	MaxPending   int
+	WriteTimeout time.Duration
+	PingInterval time.Duration
+	// AllowedOrigins lists the origins allowed besides the service's own.
+	AllowedOrigins []string
+}
+
+type Handler struct {
+	updates          Updates
+	currencyRateRepo repository.CurrencyRate
+	limits           Limits
+	upgrader         websocket.Upgrader
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+func NewHandler(
+	updates Updates,
+	currencyRateRepo repository.CurrencyRate,
+	limits Limits,
+) *Handler {
+	if limits.MaxSubscriptions <= 0 {
+		limits.MaxSubscriptions = defaultMaxSubscriptions
+	}
+
+	if limits.MaxPending <= 0 {
+		limits.MaxPending = defaultMaxPending
+	}
+
+	if limits.WriteTimeout <= 0 {
+		limits.WriteTimeout = defaultWriteTimeout
+	}
+
+	if limits.PingInterval <= 0 {
+		limits.PingInterval = defaultPingInterval
+	}
+
+	h := &Handler{
+		updates:          updates,
+		currencyRateRepo: currencyRateRepo,
+		limits:           limits,
+		shutdown:         make(chan struct{}),
+	}
+
+	h.upgrader = websocket.Upgrader{CheckOrigin: h.checkOrigin}
+
+	return h
+}
+
+// Shutdown closes all connections, http.Server.Shutdown does not track hijacked ones.
+func (h *Handler) Shutdown() {
+	h.shutdownOnce.Do(func() {
+		close(h.shutdown)
+	})
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := ctx.Err(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is shutting down"})
+
+		return
+	}
+
+	select {
+	case <-h.shutdown:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is shutting down"})
+
+		return
+	default:
+	}
+
+	// Upgrade replies with an HTTP error itself.
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	newConnection(ws, h).serve()
+}
+
+func (h *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(h.limits.AllowedOrigins, origin) {
+		return true
+	}
+
+	return origin == "http://"+r.Host || origin == "https://"+r.Host
+}
@@ -0,0 +1,220 @@
+package live
+
+import (
+	"context"
+	"main/internal/api"
+	"main/internal/repository/memory"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+)
+
+type MockUpdates struct {
+	updates chan api.Response
+}
+
+func newTable(eur string) api.Response {
+	return api.Response{
+		Base: "USD",
+		Rates: map[string]decimal.Decimal{
+			"USD": decimal.NewFromInt(1),
+			"EUR": decimal.RequireFromString(eur),
+			"GBP": decimal.RequireFromString("0.743653"),
+		},
+	}
+}
+
+func (m *MockUpdates) GetCurrencyRates(_ context.Context, currencies []string) (api.Response, error) {
+	return newTable("0.869136").Filter(currencies)
+}
+
+func (m *MockUpdates) Subscribe() (<-chan api.Response, func()) {
+	return m.updates, func() {}
+}
+
+func dial(t *testing.T, limits Limits) (*Handler, *MockUpdates, *websocket.Conn) {
+	t.Helper()
+
+	updates := &MockUpdates{updates: make(chan api.Response)}
+	handler := NewHandler(updates, memory.NewCurrencyRateRepo(), limits)
+
+	router := gin.New()
+	router.GET("/live", handler.Handle)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/live", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+
+	t.Cleanup(func() { ws.Close() })
+
+	return handler, updates, ws
+}
+
+func send(t *testing.T, ws *websocket.Conn, req Request) {
+	t.Helper()
+
+	if err := ws.WriteJSON(req); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+}
+
+func receive(t *testing.T, ws *websocket.Conn) Message {
+	t.Helper()
+
+	_ = ws.SetReadDeadline(time.Now().Add(time.Second))
+
+	var msg Message
+	if err := ws.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+
+	return msg
+}
+
+func TestHandler_Subscriptions(t *testing.T) {
+	_, updates, ws := dial(t, Limits{})
+
+	rate := Request{Action: actionSubscribe, Type: kindRate, From: "USD", To: "EUR"}
+	send(t, ws, rate)
+
+	tests := []Message{
+		{Type: typeSubscribed, Subscription: "rate:USD:EUR"},
+		{Type: kindRate, Subscription: "rate:USD:EUR", From: "USD", To: "EUR", Rate: "0.86913600"},
+	}
+
+	for _, want := range tests {
+		if got := receive(t, ws); got != want {
+			t.Errorf("subscribe got = %+v, want %+v", got, want)
+		}
+	}
+
+	// The EUR rate did not change, only the second table is pushed.
+	updates.updates <- newTable("0.869136")
+	updates.updates <- newTable("0.869221")
+
+	want := Message{Type: kindRate, Subscription: "rate:USD:EUR", From: "USD", To: "EUR", Rate: "0.86922100"}
+	if got := receive(t, ws); got != want {
+		t.Errorf("update got = %+v, want %+v", got, want)
+	}
+
+	send(t, ws, Request{Action: actionSubscribe, Type: kindExchange, From: "USDT", To: "WBTC", Amount: "1"})
+
+	tests = []Message{
+		{Type: typeSubscribed, Subscription: "exchange:USDT:WBTC:1"},
+		{Type: kindExchange, Subscription: "exchange:USDT:WBTC:1", From: "USDT", To: "WBTC", Amount: "0.00001751"},
+	}
+
+	for _, want := range tests {
+		if got := receive(t, ws); got != want {
+			t.Errorf("exchange subscribe got = %+v, want %+v", got, want)
+		}
+	}
+
+	rate.Action = actionUnsubscribe
+	send(t, ws, rate)
+
+	if got := receive(t, ws); got != (Message{Type: typeUnsubscribed, Subscription: "rate:USD:EUR"}) {
+		t.Errorf("unsubscribe got = %+v", got)
+	}
+}
+
+func TestHandler_InvalidRequests(t *testing.T) {
+	_, _, ws := dial(t, Limits{MaxSubscriptions: 1})
+
+	tests := []struct {
+		name    string
+		request any
+		wantErr string
+	}{
+		{name: "malformed json", request: "subscribe", wantErr: "invalid request"},
+		{name: "unknown action", request: Request{Action: "buy"}, wantErr: "unknown action"},
+		{name: "unknown type", request: Request{Action: actionSubscribe, Type: "candle", From: "USD", To: "EUR"}, wantErr: "unknown subscription type"},
+		{name: "same currencies", request: Request{Action: actionSubscribe, Type: kindRate, From: "USD", To: "USD"}, wantErr: "invalid request"},
+		{name: "unknown currency", request: Request{Action: actionSubscribe, Type: kindRate, From: "USD", To: "AAA"}, wantErr: "unknown currency"},
+		{name: "negative amount", request: Request{Action: actionSubscribe, Type: kindExchange, From: "USDT", To: "WBTC", Amount: "-1"}, wantErr: "positive"},
+		{name: "not subscribed", request: Request{Action: actionUnsubscribe, Type: kindRate, From: "USD", To: "EUR"}, wantErr: "not subscribed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.WriteJSON(tt.request); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+
+			got := receive(t, ws)
+			if got.Type != typeError || !strings.Contains(got.Error, tt.wantErr) {
+				t.Errorf("reply got = %+v, want error containing %q", got, tt.wantErr)
+			}
+		})
+	}
+
+	send(t, ws, Request{Action: actionSubscribe, Type: kindRate, From: "USD", To: "EUR"})
+	receive(t, ws)
+	receive(t, ws)
+
+	send(t, ws, Request{Action: actionSubscribe, Type: kindRate, From: "USD", To: "GBP"})
+
+	if got := receive(t, ws); got.Type != typeError || !strings.Contains(got.Error, "limit of 1") {
+		t.Errorf("subscription over the limit got = %+v", got)
+	}
+}
+
+func TestHandler_Shutdown(t *testing.T) {
+	handler, _, ws := dial(t, Limits{})
+
+	send(t, ws, Request{Action: actionSubscribe, Type: kindRate, From: "USD", To: "EUR"})
+	receive(t, ws)
+
+	handler.Shutdown()
+
+	_ = ws.SetReadDeadline(time.Now().Add(time.Second))
+
+	for {
+		_, _, err := ws.ReadMessage()
+		if err == nil {
+			continue
+		}
+
+		if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Errorf("ReadMessage() error = %v, want going away close", err)
+		}
+
+		break
+	}
+}
+
+func TestConnection_QuotesConflated(t *testing.T) {
+	c := newConnection(nil, &Handler{limits: Limits{MaxPending: 4}})
+
+	c.push(Message{Type: kindRate, Subscription: "rate:USD:EUR", Rate: "0.1"})
+	c.push(Message{Type: kindRate, Subscription: "rate:USD:GBP", Rate: "0.2"})
+	c.push(Message{Type: kindRate, Subscription: "rate:USD:EUR", Rate: "0.3"})
+	c.reply(Message{Type: typeSubscribed, Subscription: "rate:USD:PLN"})
+
+	batch := c.drain()
+
+	want := []Message{
+		{Type: typeSubscribed, Subscription: "rate:USD:PLN"},
+		{Type: kindRate, Subscription: "rate:USD:EUR", Rate: "0.3"},
+		{Type: kindRate, Subscription: "rate:USD:GBP", Rate: "0.2"},
+	}
+
+	if len(batch) != len(want) {
+		t.Fatalf("drain() got = %+v, want %+v", batch, want)
+	}
+
+	for i := range want {
+		if batch[i] != want[i] {
+			t.Errorf("drain()[%d] got = %+v, want %+v", i, batch[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/handlers/exchange"
+	"main/internal/handlers/rates"
+	"main/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	actionSubscribe   = "subscribe"
+	actionUnsubscribe = "unsubscribe"
+
+	kindRate     = "rate"
+	kindExchange = "exchange"
+
+	typeSubscribed   = "subscribed"
+	typeUnsubscribed = "unsubscribed"
+	typeError        = "error"
+)
+
+// Request is a message sent by the client.
+type Request struct {
+	Action string `json:"action"`
+	Type   string `json:"type"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount string `json:"amount,omitempty"`
+}
+
+// Message is sent to the client, Type tells which of the fields are set.
+type Message struct {
+	Type         string      `json:"type"`
+	Subscription string      `json:"subscription,omitempty"`
+	From         string      `json:"from,omitempty"`
+	To           string      `json:"to,omitempty"`
+	Rate         json.Number `json:"rate,omitempty"`
+	Amount       json.Number `json:"amount,omitempty"`
+	Error        string      `json:"error,omitempty"`
+}
+
+type subscription struct {
+	id     string
+	kind   string
+	from   string
+	to     string
+	amount decimal.Decimal
+}
+
+func parseSubscription(req Request) (subscription, error) {
+	if req.From == "" || req.To == "" {
+		return subscription{}, errs.ErrEmptyParam
+	}
+
+	if req.From == req.To {
+		return subscription{}, errs.ErrBadRequest
+	}
+
+	sub := subscription{
+		kind: req.Type,
+		from: req.From,
+		to:   req.To,
+	}
+
+	switch req.Type {
+	case kindRate:
+		sub.id = fmt.Sprintf("%s:%s:%s", kindRate, req.From, req.To)
+	case kindExchange:
+		amount, err := decimal.NewFromString(req.Amount)
+		if err != nil {
+			return subscription{}, errs.ErrAmountNotNumber
+		}
+
+		if amount.IsNegative() {
+			return subscription{}, errs.ErrNegativeAmount
+		}
+
+		sub.amount = amount
+		sub.id = fmt.Sprintf("%s:%s:%s:%s", kindExchange, req.From, req.To, amount.String())
+	default:
+		return subscription{}, fmt.Errorf("%w: unknown subscription type %q", errs.ErrBadRequest, req.Type)
+	}
+
+	return sub, nil
+}
+
+// quote prices the subscription, rates come from table and exchange amounts from the repository.
+func quote(sub subscription, table api.Response, currencyRateRepo repository.CurrencyRate) (Message, error) {
+	if sub.kind == kindExchange {
+		resp, err := exchange.Quote(currencyRateRepo, sub.from, sub.to, sub.amount)
+		if err != nil {
+			return Message{}, err
+		}
+
+		return Message{
+			Type:         kindExchange,
+			Subscription: sub.id,
+			From:         sub.from,
+			To:           sub.to,
+			Amount:       resp.Amount,
+		}, nil
+	}
+
+	currencies := []string{sub.from, sub.to}
+
+	filtered, err := table.Filter(currencies)
+	if err != nil {
+		return Message{}, err
+	}
+
+	pairs, err := rates.Calculate(filtered.Rates, currencies)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Type:         kindRate,
+		Subscription: sub.id,
+		From:         sub.from,
+		To:           sub.to,
+		Rate:         pairs[0].Rate,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/api"
+	"main/internal/errs"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countBaseRates returns one-to-many rates from base. Rates quoted in base are asked
+// from the provider first; when its plan does not allow changing the base, they are
+// rebased locally from the default table.
+func (h *Handler) countBaseRates(
+	ctx context.Context,
+	c *gin.Context,
+	base string,
+) ([]Response, error) {
+	targets, err := parseTargets(c.Query("currencies"), base)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := h.baseRates(ctx, base, targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency rates: %w", err)
+	}
+
+	setProvider(c, table)
+
+	combinations := make([][]string, 0, len(targets))
+	for _, target := range targets {
+		combinations = append(combinations, []string{base, target})
+	}
+
+	return calculateCurrencyRates(table.Rates, combinations)
+}
+
+func (h *Handler) baseRates(
+	ctx context.Context,
+	base string,
+	targets []string,
+) (api.Response, error) {
+	currencies := append(slices.Clone(targets), base)
+
+	if baseAPI, ok := h.currencyRateAPI.(api.BaseCurrencyRate); ok {
+		resp, err := baseAPI.GetCurrencyRatesForBase(ctx, base, currencies)
+		if err == nil || !errors.Is(err, errs.ErrNotAllowed) {
+			return resp, err
+		}
+	}
+
+	return h.currencyRateAPI.GetCurrencyRates(ctx, currencies)
+}
+
+func parseTargets(param, base string) ([]string, error) {
+	if param == "" {
+		return nil, errs.ErrEmptyParam
+	}
+
+	targets := strings.Split(param, ",")
+	if containsDuplicates(targets) || slices.Contains(targets, base) {
+		return nil, errs.ErrBadRequest
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,112 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/internal/errs"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	percentPrecision = 4
+)
+
+var changePeriods = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// Change compares a rate with the one published at the end of the day Period ago.
+type Change struct {
+	Period       string      `json:"period"`
+	PreviousRate json.Number `json:"previousRate"`
+	Absolute     json.Number `json:"absolute"`
+	Percent      json.Number `json:"percent,omitempty"`
+}
+
+func parseChange(param string) (time.Duration, error) {
+	if param == "" {
+		return 0, nil
+	}
+
+	period, ok := changePeriods[param]
+	if !ok {
+		return 0, errs.ErrBadRequest
+	}
+
+	return period, nil
+}
+
+// addChange fills in the change of every pair, the historical tables are cached,
+// so the same period costs one upstream request a day.
+func (h *Handler) addChange(
+	ctx context.Context,
+	responses []Response,
+	param string,
+	period time.Duration,
+) ([]Response, error) {
+	if h.historicalAPI == nil {
+		return nil, errs.ErrHistoryNotSupported
+	}
+
+	combinations := make([][]string, 0, len(responses))
+	currencies := make([]string, 0, len(responses))
+	seen := make(map[string]bool, len(responses))
+
+	for _, resp := range responses {
+		combinations = append(combinations, []string{resp.From, resp.To})
+
+		for _, currency := range []string{resp.From, resp.To} {
+			if !seen[currency] {
+				seen[currency] = true
+				currencies = append(currencies, currency)
+			}
+		}
+	}
+
+	year, month, day := h.now().UTC().Add(-period).Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	table, err := h.historicalAPI.GetHistoricalCurrencyRates(ctx, date, currencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical currency rates: %w", err)
+	}
+
+	previous, err := calculateCurrencyRates(table.Rates, combinations)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range responses {
+		current, err := decimal.NewFromString(responses[i].Rate.String())
+		if err != nil {
+			return nil, fmt.Errorf("error parsing rate %s: %w", responses[i].Rate, err)
+		}
+
+		previousRate, err := decimal.NewFromString(previous[i].Rate.String())
+		if err != nil {
+			return nil, fmt.Errorf("error parsing rate %s: %w", previous[i].Rate, err)
+		}
+
+		absolute := current.Sub(previousRate)
+
+		change := &Change{
+			Period:       param,
+			PreviousRate: previous[i].Rate,
+			Absolute:     json.Number(absolute.StringFixed(DecimalPrecision)),
+		}
+
+		if !previousRate.IsZero() {
+			percent := absolute.Mul(decimal.NewFromInt(100)).DivRound(previousRate, percentPrecision)
+			change.Percent = json.Number(percent.StringFixed(percentPrecision))
+		}
+
+		responses[i].Change = change
+	}
+
+	return responses, nil
+}
@@ -9,33 +9,45 @@ import (
 	"main/internal/errs"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
 )
 
 const (
-	decimalPrecision = 8
+	DecimalPrecision = 8
+
+	// ProviderHeader names the upstream provider the rates come from.
+	ProviderHeader = "X-Rate-Provider"
 )
 
 type Response struct {
 	From string      `json:"from"`
 	To   string      `json:"to"`
 	Rate json.Number `json:"rate"`
+	// Change is only set when asked for with the change parameter.
+	Change *Change `json:"change,omitempty"`
 }
 
 type Handler struct {
 	currencyRateAPI api.CurrencyRate
+	historicalAPI   api.HistoricalCurrencyRate
 	errorHandler    errs.ErrorHandler
+	now             func() time.Time
 }
 
+// NewHandler takes an optional historicalAPI, without it the change parameter is not supported.
 func NewHandler(
 	currencyRateAPI api.CurrencyRate,
+	historicalAPI api.HistoricalCurrencyRate,
 	errorHandler errs.ErrorHandler,
 ) *Handler {
 	return &Handler{
 		currencyRateAPI: currencyRateAPI,
+		historicalAPI:   historicalAPI,
 		errorHandler:    errorHandler,
+		now:             time.Now,
 	}
 }
 
@@ -59,19 +71,27 @@ func (h *Handler) Handle(c *gin.Context) {
 }
 
 func (h *Handler) countRates(ctx context.Context, c *gin.Context) ([]Response, error) {
-	param := c.Query("currencies")
-	if param == "" {
-		return nil, errs.ErrEmptyParam
+	period, err := parseChange(c.Query("change"))
+	if err != nil {
+		return nil, err
 	}
 
-	currencies := strings.Split(param, ",")
-	if len(currencies) < 2 || containsDuplicates(currencies) {
-		return nil, errs.ErrBadRequest
+	responses, err := h.countCurrentRates(ctx, c)
+	if err != nil || period == 0 {
+		return responses, err
 	}
 
-	currencyCombinations, err := getAllCombinations(currencies)
+	return h.addChange(ctx, responses, c.Query("change"), period)
+}
+
+func (h *Handler) countCurrentRates(ctx context.Context, c *gin.Context) ([]Response, error) {
+	if base := c.Query("base"); base != "" {
+		return h.countBaseRates(ctx, c, base)
+	}
+
+	currencies, err := ParseCurrencies(c.Query("currencies"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get combinations: %w", err)
+		return nil, err
 	}
 
 	resp, err := h.currencyRateAPI.GetCurrencyRates(ctx, currencies)
@@ -79,12 +99,38 @@ func (h *Handler) countRates(ctx context.Context, c *gin.Context) ([]Response, e
 		return nil, fmt.Errorf("failed to get currency rates: %w", err)
 	}
 
-	responses, err := calculateCurrencyRates(resp.Rates, currencyCombinations)
+	setProvider(c, resp)
+
+	return Calculate(resp.Rates, currencies)
+}
+
+func setProvider(c *gin.Context, resp api.Response) {
+	if resp.Provider != "" {
+		c.Header(ProviderHeader, resp.Provider)
+	}
+}
+
+func ParseCurrencies(param string) ([]string, error) {
+	if param == "" {
+		return nil, errs.ErrEmptyParam
+	}
+
+	currencies := strings.Split(param, ",")
+	if len(currencies) < 2 || containsDuplicates(currencies) {
+		return nil, errs.ErrBadRequest
+	}
+
+	return currencies, nil
+}
+
+// Calculate returns the exchange rate for every ordered pair of the given currencies.
+func Calculate(rates map[string]decimal.Decimal, currencies []string) ([]Response, error) {
+	currencyCombinations, err := getAllCombinations(currencies)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get combinations: %w", err)
 	}
 
-	return responses, nil
+	return calculateCurrencyRates(rates, currencyCombinations)
 }
 
 func getAllCombinations(input []string) ([][]string, error) {
@@ -116,7 +162,7 @@ func containsDuplicates(list []string) bool {
 }
 
 func calculateCurrencyRates(
-	rates map[string]float64,
+	rates map[string]decimal.Decimal,
 	currencyCombinations [][]string,
 ) ([]Response, error) {
 	responses := make([]Response, 0, len(currencyCombinations))
@@ -138,7 +184,7 @@ func calculateCurrencyRates(
 			return nil, errs.ErrCurrencyNotFound
 		}
 
-		if sourceRate == 0 {
+		if sourceRate.IsZero() {
 			return nil, errs.ErrZeroValue
 		}
 
@@ -147,12 +193,9 @@ func calculateCurrencyRates(
 			return nil, errs.ErrCurrencyNotFound
 		}
 
-		sourceDecimalRate := decimal.NewFromFloat(sourceRate)
-		targetDecimalRate := decimal.NewFromFloat(targetRate)
-
-		decimalRate := targetDecimalRate.Div(sourceDecimalRate)
+		decimalRate := targetRate.DivRound(sourceRate, DecimalPrecision)
 
-		strRate := decimalRate.StringFixed(decimalPrecision)
+		strRate := decimalRate.StringFixed(DecimalPrecision)
 		response := Response{
 			From: sourceCurrency,
 			To:   targetCurrency,
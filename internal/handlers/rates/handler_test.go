@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"main/internal/api"
+	"main/internal/api/failover"
 	"main/internal/errs"
 	"main/internal/errs/currency"
 	"net/http"
@@ -12,8 +13,10 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 )
 
 type MockCurrencyAPI struct {
@@ -55,15 +58,16 @@ func (m MockCurrencyAPI) GetCurrencyRates(
 		return api.Response{}, m.err
 	}
 
-	rates := map[string]float64{
-		"BDT": 122.251634,
-		"BHD": 0.377252,
-		"EUR": 0.869136,
-		"GBP": 0.743653,
-		"INR": 86.466554,
-		"IRR": 42125,
-		"USD": 1,
-		"MRU": 0.0,
+	rates := map[string]decimal.Decimal{
+		"BDT": decimal.RequireFromString("122.251634"),
+		"BHD": decimal.RequireFromString("0.377252"),
+		"EUR": decimal.RequireFromString("0.869136"),
+		"GBP": decimal.RequireFromString("0.743653"),
+		"INR": decimal.RequireFromString("86.466554"),
+		"IRR": decimal.RequireFromString("42125"),
+		"USD": decimal.RequireFromString("1"),
+		"MRU": decimal.RequireFromString("0.0"),
+		"VES": decimal.RequireFromString("1234567890123.123456789"),
 	}
 
 	for _, currency := range currencies {
@@ -80,10 +84,57 @@ func (m MockCurrencyAPI) GetCurrencyRates(
 	}, nil
 }
 
+type MockBaseCurrencyAPI struct {
+	MockCurrencyAPI
+	baseErr error
+}
+
+func (m MockBaseCurrencyAPI) GetCurrencyRatesForBase(
+	_ context.Context, base string, currencies []string,
+) (api.Response, error) {
+	if m.baseErr != nil {
+		return api.Response{}, m.baseErr
+	}
+
+	if base != "EUR" {
+		return api.Response{}, errs.ErrInvalidBase
+	}
+
+	resp := api.Response{
+		Base:      "EUR",
+		Rates:     map[string]decimal.Decimal{"EUR": decimal.RequireFromString("1"), "USD": decimal.RequireFromString("1.15"), "GBP": decimal.RequireFromString("0.8552")},
+		Timestamp: 1750240800,
+	}
+
+	return resp.Filter(currencies)
+}
+
+// MockHistoricalAPI only knows the tables published a day and a week before the test's now.
+type MockHistoricalAPI struct{}
+
+func (MockHistoricalAPI) GetHistoricalCurrencyRates(
+	_ context.Context, date time.Time, currencies []string,
+) (api.Response, error) {
+	history := map[string]map[string]decimal.Decimal{
+		"2025-06-17": {"USD": decimal.RequireFromString("1"), "EUR": decimal.RequireFromString("0.9"), "GBP": decimal.RequireFromString("0.8")},
+		"2025-06-11": {"USD": decimal.RequireFromString("1"), "EUR": decimal.RequireFromString("0.9"), "GBP": decimal.RequireFromString("0.743653")},
+	}
+
+	rates, ok := history[date.Format("2006-01-02")]
+	if !ok {
+		return api.Response{}, errs.ErrAPIResponse
+	}
+
+	resp := api.Response{Base: "USD", Rates: rates, Timestamp: int(date.Unix())}
+
+	return resp.Filter(currencies)
+}
+
 func TestHandler_Handle(t *testing.T) {
 	tests := []struct {
 		name            string
 		currencyRateAPI api.CurrencyRate
+		historicalAPI   api.HistoricalCurrencyRate
 		errorHandler    errs.ErrorHandler
 		url             string
 		wantStatus      int
@@ -119,6 +170,26 @@ func TestHandler_Handle(t *testing.T) {
 			wantBody: []byte(
 				`[{"from":"USD","to":"BDT","rate":122.25163400},{"from":"USD","to":"BHD","rate":0.37725200},{"from":"USD","to":"INR","rate":86.46655400},{"from":"BDT","to":"USD","rate":0.00817985},{"from":"BDT","to":"BHD","rate":0.00308586},{"from":"BDT","to":"INR","rate":0.70728342},{"from":"BHD","to":"USD","rate":2.65074804},{"from":"BHD","to":"BDT","rate":324.05827935},{"from":"BHD","to":"INR","rate":229.20104864},{"from":"INR","to":"USD","rate":0.01156517},{"from":"INR","to":"BDT","rate":1.41386037},{"from":"INR","to":"BHD","rate":0.00436298}]`),
 		},
+		{
+			name:            "digits beyond float64 precision kept, status ok",
+			currencyRateAPI: NewMockAPISuccess(),
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=USD,VES",
+			wantStatus:      http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"USD","to":"VES","rate":1234567890123.12345679},{"from":"VES","to":"USD","rate":0.00000000}]`,
+			),
+		},
+		{
+			name:            "cross rate without float drift, status ok",
+			currencyRateAPI: NewMockAPISuccess(),
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=EUR,VES",
+			wantStatus:      http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"EUR","to":"VES","rate":1420454209839.56878646},{"from":"VES","to":"EUR","rate":0.00000000}]`,
+			),
+		},
 		{
 			name:         "test param USD, status 400",
 			errorHandler: currency.NewErrorHandler(),
@@ -144,11 +215,11 @@ func TestHandler_Handle(t *testing.T) {
 			wantStatus:   http.StatusBadRequest,
 		},
 		{
-			name:            "test api failure, status 400",
+			name:            "test api failure, status 502",
 			currencyRateAPI: NewMockAPIFailureResp(),
 			errorHandler:    currency.NewErrorHandler(),
 			url:             "/rates?currencies=BTC,USD",
-			wantStatus:      http.StatusBadRequest,
+			wantStatus:      http.StatusBadGateway,
 		},
 		{
 			name:            "calculate for non existing currencies",
@@ -182,6 +253,131 @@ func TestHandler_Handle(t *testing.T) {
 			wantStatus:      http.StatusNotFound,
 			wantErr:         "error unknown currency",
 		},
+		{
+			name:            "invalid app id upstream, status 502",
+			currencyRateAPI: MockCurrencyAPI{err: errs.ErrInvalidAppID},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=GBP,USD",
+			wantStatus:      http.StatusBadGateway,
+			wantErr:         errs.ErrInvalidAppID.Error(),
+		},
+		{
+			name:            "upstream plan does not allow request, status 503",
+			currencyRateAPI: MockCurrencyAPI{err: errs.ErrNotAllowed},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=GBP,USD",
+			wantStatus:      http.StatusServiceUnavailable,
+			wantErr:         errs.ErrNotAllowed.Error(),
+		},
+		{
+			name:            "upstream quota exceeded, status 429",
+			currencyRateAPI: MockCurrencyAPI{err: errs.ErrQuotaExceeded},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=GBP,USD",
+			wantStatus:      http.StatusTooManyRequests,
+			wantErr:         errs.ErrQuotaExceeded.Error(),
+		},
+		{
+			name:            "base EUR rebased locally, status ok",
+			currencyRateAPI: NewMockAPISuccess(),
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?base=EUR&currencies=USD,GBP",
+			wantStatus:      http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"EUR","to":"USD","rate":1.15056792},{"from":"EUR","to":"GBP","rate":0.85562329}]`,
+			),
+		},
+		{
+			name:            "base EUR quoted by provider, status ok",
+			currencyRateAPI: MockBaseCurrencyAPI{},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?base=EUR&currencies=USD,GBP",
+			wantStatus:      http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"EUR","to":"USD","rate":1.15000000},{"from":"EUR","to":"GBP","rate":0.85520000}]`,
+			),
+		},
+		{
+			name:            "base not allowed by provider plan falls back to local rebasing, status ok",
+			currencyRateAPI: MockBaseCurrencyAPI{baseErr: errs.ErrNotAllowed},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?base=EUR&currencies=USD",
+			wantStatus:      http.StatusOK,
+			wantBody:        []byte(`[{"from":"EUR","to":"USD","rate":1.15056792}]`),
+		},
+		{
+			name:            "base unknown to provider, status 400",
+			currencyRateAPI: MockBaseCurrencyAPI{},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?base=XYZ&currencies=USD",
+			wantStatus:      http.StatusBadRequest,
+			wantErr:         errs.ErrInvalidBase.Error(),
+		},
+		{
+			name:            "base repeated in currencies, status 400",
+			currencyRateAPI: NewMockAPISuccess(),
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?base=EUR&currencies=USD,EUR",
+			wantStatus:      http.StatusBadRequest,
+		},
+		{
+			name:            "base without currencies, status 400",
+			currencyRateAPI: NewMockAPISuccess(),
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?base=EUR",
+			wantStatus:      http.StatusBadRequest,
+		},
+		{
+			name:            "change over 24h, status ok",
+			currencyRateAPI: NewMockAPISuccess(),
+			historicalAPI:   MockHistoricalAPI{},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=USD,GBP&change=24h",
+			wantStatus:      http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"USD","to":"GBP","rate":0.74365300,"change":{"period":"24h","previousRate":0.80000000,"absolute":-0.05634700,"percent":-7.0434}},` +
+					`{"from":"GBP","to":"USD","rate":1.34471319,"change":{"period":"24h","previousRate":1.25000000,"absolute":0.09471319,"percent":7.5771}}]`,
+			),
+		},
+		{
+			name:            "unchanged over 7d, status ok",
+			currencyRateAPI: NewMockAPISuccess(),
+			historicalAPI:   MockHistoricalAPI{},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=USD,GBP&change=7d",
+			wantStatus:      http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"USD","to":"GBP","rate":0.74365300,"change":{"period":"7d","previousRate":0.74365300,"absolute":0.00000000,"percent":0.0000}},` +
+					`{"from":"GBP","to":"USD","rate":1.34471319,"change":{"period":"7d","previousRate":1.34471319,"absolute":0.00000000,"percent":0.0000}}]`,
+			),
+		},
+		{
+			name:            "change for base, status ok",
+			currencyRateAPI: MockBaseCurrencyAPI{},
+			historicalAPI:   MockHistoricalAPI{},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?base=EUR&currencies=USD&change=24h",
+			wantStatus:      http.StatusOK,
+			wantBody: []byte(
+				`[{"from":"EUR","to":"USD","rate":1.15000000,"change":{"period":"24h","previousRate":1.11111111,"absolute":0.03888889,"percent":3.5000}}]`,
+			),
+		},
+		{
+			name:            "change over unsupported period, status 400",
+			currencyRateAPI: NewMockAPISuccess(),
+			historicalAPI:   MockHistoricalAPI{},
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=USD,GBP&change=1y",
+			wantStatus:      http.StatusBadRequest,
+		},
+		{
+			name:            "change without historical rates, status 501",
+			currencyRateAPI: NewMockAPISuccess(),
+			errorHandler:    currency.NewErrorHandler(),
+			url:             "/rates?currencies=USD,GBP&change=24h",
+			wantStatus:      http.StatusNotImplemented,
+			wantErr:         errs.ErrHistoryNotSupported.Error(),
+		},
 		{
 			name:            "error divide by zero",
 			currencyRateAPI: NewMockZeroValueErr(),
@@ -201,7 +397,8 @@ func TestHandler_Handle(t *testing.T) {
 			c.Request = httptest.NewRequestWithContext(
 				context.Background(), "GET", tt.url, nil)
 
-			handler := NewHandler(tt.currencyRateAPI, tt.errorHandler)
+			handler := NewHandler(tt.currencyRateAPI, tt.historicalAPI, tt.errorHandler)
+			handler.now = func() time.Time { return time.Date(2025, 6, 18, 10, 0, 0, 0, time.UTC) }
 			handler.Handle(c)
 
 			if recorder.Code != tt.wantStatus {
@@ -226,3 +423,31 @@ func TestHandler_Handle(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ProviderHeader(t *testing.T) {
+	chain, err := failover.New(
+		failover.Provider{Name: "primary", API: NewMockAPIFailureResp()},
+		failover.Provider{Name: "backup", API: NewMockAPISuccess()},
+	)
+	if err != nil {
+		t.Fatalf("failover.New() unexpected error: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/rates", NewHandler(chain, nil, currency.NewErrorHandler()).Handle)
+
+	for _, url := range []string{"/rates?currencies=USD,EUR", "/rates?base=USD&currencies=EUR"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Handle(%s) status = %v, want %v", url, recorder.Code, http.StatusOK)
+		}
+
+		if got := recorder.Header().Get(ProviderHeader); got != "backup" {
+			t.Errorf("Handle(%s) %s = %q, want %q", url, ProviderHeader, got, "backup")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package rates
+
+import (
+	"context"
+	"main/internal/api/openexchange"
+	"main/internal/api/recorder"
+	"main/internal/errs/currency"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandler_HandleRecordedUpstream(t *testing.T) {
+	transport, err := recorder.New(recorder.ModeReplay, "testdata/fixtures", nil)
+	if err != nil {
+		t.Fatalf("recorder.New() unexpected error: %v", err)
+	}
+
+	upstream, err := openexchange.New(
+		"https://openexchangerates.org/api/",
+		"app-id",
+		openexchange.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	if err != nil {
+		t.Fatalf("openexchange.New() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "recorded latest rates, status ok",
+			url:        "/rates?currencies=EUR,PLN",
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"from":"EUR","to":"PLN","rate":4.25750976},{"from":"PLN","to":"EUR","rate":0.23487909}]`,
+		},
+		{
+			name:       "base not allowed by the recorded plan, rebased locally, status ok",
+			url:        "/rates?base=GBP&currencies=JPY,BTC",
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"from":"GBP","to":"JPY","rate":195.23924465},{"from":"GBP","to":"BTC","rate":0.00001278}]`,
+		},
+		{
+			name:       "currency missing from the recorded table, status 404",
+			url:        "/rates?currencies=EUR,XAU",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(recorder)
+
+			c.Request = httptest.NewRequestWithContext(
+				context.Background(), "GET", tt.url, nil)
+
+			handler := NewHandler(upstream, nil, currency.NewErrorHandler())
+			handler.Handle(c)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %d want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Errorf("Handle() got = %s, want %s", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
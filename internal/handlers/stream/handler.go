@@ -0,0 +1,160 @@
+package stream
+
+import (
+	"errors"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/handlers/rates"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	eventRates = "rates"
+	eventError = "error"
+	eventClose = "close"
+
+	defaultHeartbeat = 15 * time.Second
+)
+
+// Updates serves the current rate table and notifies about every change of it.
+type Updates interface {
+	api.CurrencyRate
+	Subscribe() (<-chan api.Response, func())
+}
+
+type Handler struct {
+	updates      Updates
+	errorHandler errs.ErrorHandler
+	heartbeat    time.Duration
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+func NewHandler(
+	updates Updates,
+	errorHandler errs.ErrorHandler,
+	heartbeat time.Duration,
+) *Handler {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+
+	return &Handler{
+		updates:      updates,
+		errorHandler: errorHandler,
+		heartbeat:    heartbeat,
+		shutdown:     make(chan struct{}),
+	}
+}
+
+// Shutdown ends all open streams, http.Server.Shutdown does not wait for them otherwise.
+func (h *Handler) Shutdown() {
+	h.shutdownOnce.Do(func() {
+		close(h.shutdown)
+	})
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := ctx.Err(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is shutting down"})
+
+		return
+	}
+
+	currencies, err := rates.ParseCurrencies(c.Query("currencies"))
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	// Subscribe first, so no change between the first table and the stream is missed.
+	updates, cancel := h.updates.Subscribe()
+	defer cancel()
+
+	table, err := h.updates.GetCurrencyRates(ctx, currencies)
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	last, err := rates.Calculate(table.Rates, currencies)
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	// The stream outlives the server write timeout.
+	err = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	h.send(c, eventRates, last)
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.shutdown:
+			h.send(c, eventClose, gin.H{"reason": "service is shutting down"})
+
+			return
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+
+			c.Writer.Flush()
+		case table := <-updates:
+			responses, err := h.pairs(table, currencies)
+			if err != nil {
+				h.send(c, eventError, gin.H{"error": err.Error()})
+
+				continue
+			}
+
+			// Other currencies moved, nothing new for this client.
+			if reflect.DeepEqual(responses, last) {
+				continue
+			}
+
+			last = responses
+			h.send(c, eventRates, responses)
+		}
+	}
+}
+
+func (h *Handler) pairs(table api.Response, currencies []string) ([]rates.Response, error) {
+	filtered, err := table.Filter(currencies)
+	if err != nil {
+		return nil, err
+	}
+
+	return rates.Calculate(filtered.Rates, currencies)
+}
+
+func (h *Handler) send(c *gin.Context, event string, data any) {
+	c.SSEvent(event, data)
+	c.Writer.Flush()
+}
@@ -0,0 +1,165 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"main/internal/api"
+	"main/internal/errs/currency"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type MockUpdates struct {
+	updates  chan api.Response
+	canceled atomic.Bool
+}
+
+func newTable(eur, gbp string) api.Response {
+	return api.Response{
+		Base: "USD",
+		Rates: map[string]decimal.Decimal{
+			"USD": decimal.NewFromInt(1),
+			"EUR": decimal.RequireFromString(eur),
+			"GBP": decimal.RequireFromString(gbp),
+		},
+	}
+}
+
+func (m *MockUpdates) GetCurrencyRates(_ context.Context, currencies []string) (api.Response, error) {
+	return newTable("0.869136", "0.743653").Filter(currencies)
+}
+
+func (m *MockUpdates) Subscribe() (<-chan api.Response, func()) {
+	return m.updates, func() { m.canceled.Store(true) }
+}
+
+// readEvent returns the next event or comment block of the stream.
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+
+	var lines []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString() error = %v, read %q", err, lines)
+		}
+
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+func TestHandler_Handle(t *testing.T) {
+	updates := &MockUpdates{updates: make(chan api.Response, 1)}
+	handler := NewHandler(updates, currency.NewErrorHandler(), 50*time.Millisecond)
+
+	router := gin.New()
+	router.GET("/rates/stream", handler.Handle)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rates/stream?currencies=USD,EUR")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream got status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	want := "event:rates\n" +
+		`data:[{"from":"USD","to":"EUR","rate":0.86913600},{"from":"EUR","to":"USD","rate":1.15056792}]`
+	if got := readEvent(t, reader); got != want {
+		t.Errorf("first event got = %q, want %q", got, want)
+	}
+
+	// Only GBP moved, the client does not follow it.
+	updates.updates <- newTable("0.869136", "0.75")
+	updates.updates <- newTable("0.869221", "0.75")
+
+	want = "event:rates\n" +
+		`data:[{"from":"USD","to":"EUR","rate":0.86922100},{"from":"EUR","to":"USD","rate":1.15045541}]`
+
+	for {
+		got := readEvent(t, reader)
+		if got == ": heartbeat" {
+			continue
+		}
+
+		if got != want {
+			t.Errorf("update event got = %q, want %q", got, want)
+		}
+
+		break
+	}
+
+	handler.Shutdown()
+
+	for {
+		got := readEvent(t, reader)
+		if got == ": heartbeat" {
+			continue
+		}
+
+		if want := "event:close\n" + `data:{"reason":"service is shutting down"}`; got != want {
+			t.Errorf("shutdown event got = %q, want %q", got, want)
+		}
+
+		break
+	}
+
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Errorf("stream still open after shutdown")
+	}
+
+	if !updates.canceled.Load() {
+		t.Errorf("subscription not canceled")
+	}
+}
+
+func TestHandler_HandleInvalidParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{name: "missing currencies", url: "/rates/stream", wantStatus: http.StatusBadRequest},
+		{name: "single currency", url: "/rates/stream?currencies=USD", wantStatus: http.StatusBadRequest},
+		{name: "unknown currency", url: "/rates/stream?currencies=USD,AAA", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(recorder)
+
+			c.Request = httptest.NewRequestWithContext(
+				context.Background(), "GET", tt.url, nil)
+
+			handler := NewHandler(&MockUpdates{updates: make(chan api.Response)}, currency.NewErrorHandler(), time.Second)
+			handler.Handle(c)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %d want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
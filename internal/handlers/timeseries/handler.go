@@ -0,0 +1,160 @@
+package timeseries
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/internal/api"
+	"main/internal/errs"
+	"main/internal/handlers/history"
+	"main/internal/handlers/rates"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	day        = 24 * time.Hour
+
+	defaultMaxDays = 31
+)
+
+type Response struct {
+	Date string      `json:"date"`
+	From string      `json:"from"`
+	To   string      `json:"to"`
+	Rate json.Number `json:"rate"`
+}
+
+type Handler struct {
+	currencyRateAPI api.HistoricalCurrencyRate
+	errorHandler    errs.ErrorHandler
+	maxDays         int
+	concurrency     int
+	now             func() time.Time
+}
+
+// NewHandler limits the ranges to defaultMaxDays when maxDays is not positive.
+func NewHandler(
+	currencyRateAPI api.HistoricalCurrencyRate,
+	errorHandler errs.ErrorHandler,
+	maxDays int,
+	concurrency int,
+) *Handler {
+	if maxDays <= 0 {
+		maxDays = defaultMaxDays
+	}
+
+	return &Handler{
+		currencyRateAPI: currencyRateAPI,
+		errorHandler:    errorHandler,
+		maxDays:         maxDays,
+		concurrency:     max(concurrency, 1),
+		now:             time.Now,
+	}
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := ctx.Err(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is shutting down"})
+
+		return
+	}
+
+	series, err := h.countSeries(ctx, c)
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *Handler) countSeries(ctx context.Context, c *gin.Context) ([]Response, error) {
+	sourceCurrency := c.Query("from")
+	targetCurrency := c.Query("to")
+
+	if sourceCurrency == "" || targetCurrency == "" {
+		return nil, errs.ErrEmptyParam
+	}
+
+	if sourceCurrency == targetCurrency {
+		return nil, errs.ErrBadRequest
+	}
+
+	days, err := h.parseRange(c.Query("start"), c.Query("end"))
+	if err != nil {
+		return nil, err
+	}
+
+	pair := []string{sourceCurrency, targetCurrency}
+	series := make([]Response, len(days))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(h.concurrency)
+
+	for i, date := range days {
+		group.Go(func() error {
+			resp, err := h.currencyRateAPI.GetHistoricalCurrencyRates(groupCtx, date, pair)
+			if err != nil {
+				return fmt.Errorf("failed to get rates for %s: %w", date.Format(dateLayout), err)
+			}
+
+			pairRates, err := rates.Calculate(resp.Rates, pair)
+			if err != nil {
+				return err
+			}
+
+			series[i] = Response{
+				Date: date.Format(dateLayout),
+				From: pairRates[0].From,
+				To:   pairRates[0].To,
+				Rate: pairRates[0].Rate,
+			}
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (h *Handler) parseRange(startParam, endParam string) ([]time.Time, error) {
+	now := h.now()
+
+	start, err := history.ParseDate(startParam, now)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := history.ParseDate(endParam, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if end.Before(start) {
+		return nil, errs.ErrInvalidDateRange
+	}
+
+	length := int(end.Sub(start)/day) + 1
+	if length > h.maxDays {
+		return nil, errs.ErrInvalidDateRange
+	}
+
+	days := make([]time.Time, 0, length)
+	for date := start; !date.After(end); date = date.Add(day) {
+		days = append(days, date)
+	}
+
+	return days, nil
+}
@@ -0,0 +1,189 @@
+package timeseries
+
+import (
+	"context"
+	"main/internal/api"
+	"main/internal/errs/currency"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type MockHistoricalAPI struct {
+	calls    atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (m *MockHistoricalAPI) GetHistoricalCurrencyRates(
+	_ context.Context, date time.Time, currencies []string,
+) (api.Response, error) {
+	m.calls.Add(1)
+
+	current := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+
+	for {
+		peak := m.peak.Load()
+		if current <= peak || m.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	resp := api.Response{
+		Base: "USD",
+		Rates: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("1"),
+			"EUR": decimal.RequireFromString("0.9"),
+			"PLN": decimal.RequireFromString("3.6").Add(decimal.New(int64(date.Day()), -2)),
+		},
+		Timestamp: int(date.Unix()),
+	}
+
+	return resp.Filter(currencies)
+}
+
+func TestHandler_Handle(t *testing.T) {
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantBody   []byte
+		wantCalls  int32
+	}{
+		{
+			name:       "three days of EUR/PLN, status ok",
+			url:        "/rates/timeseries?from=EUR&to=PLN&start=2025-06-01&end=2025-06-03",
+			wantStatus: http.StatusOK,
+			wantBody: []byte(
+				`[{"date":"2025-06-01","from":"EUR","to":"PLN","rate":4.01111111},{"date":"2025-06-02","from":"EUR","to":"PLN","rate":4.02222222},{"date":"2025-06-03","from":"EUR","to":"PLN","rate":4.03333333}]`,
+			),
+			wantCalls: 3,
+		},
+		{
+			name:       "single day range, status ok",
+			url:        "/rates/timeseries?from=PLN&to=USD&start=2025-06-10&end=2025-06-10",
+			wantStatus: http.StatusOK,
+			wantBody:   []byte(`[{"date":"2025-06-10","from":"PLN","to":"USD","rate":0.27027027}]`),
+			wantCalls:  1,
+		},
+		{
+			name:       "range longer than limit, status 400",
+			url:        "/rates/timeseries?from=EUR&to=PLN&start=2025-01-01&end=2025-06-01",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "end before start, status 400",
+			url:        "/rates/timeseries?from=EUR&to=PLN&start=2025-06-03&end=2025-06-01",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "end in the future, status 400",
+			url:        "/rates/timeseries?from=EUR&to=PLN&start=2025-06-17&end=2025-06-19",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "same currencies, status 400",
+			url:        "/rates/timeseries?from=EUR&to=EUR&start=2025-06-01&end=2025-06-03",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing currency, status 400",
+			url:        "/rates/timeseries?from=EUR&start=2025-06-01&end=2025-06-03",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown currency, status 404",
+			url:        "/rates/timeseries?from=EUR&to=AAA&start=2025-06-01&end=2025-06-03",
+			wantStatus: http.StatusNotFound,
+			wantCalls:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(recorder)
+
+			c.Request = httptest.NewRequestWithContext(
+				context.Background(), "GET", tt.url, nil)
+
+			mockAPI := &MockHistoricalAPI{}
+
+			handler := NewHandler(mockAPI, currency.NewErrorHandler(), 31, 2)
+			handler.now = func() time.Time { return now }
+			handler.Handle(c)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %d want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantBody != nil && !reflect.DeepEqual(recorder.Body.Bytes(), tt.wantBody) {
+				t.Errorf("handler returned body = %s, want %s", recorder.Body.Bytes(), tt.wantBody)
+			}
+
+			if tt.wantCalls != 0 && mockAPI.calls.Load() > tt.wantCalls {
+				t.Errorf("api called %d times, want at most %d", mockAPI.calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHandler_BoundedConcurrency(t *testing.T) {
+	mockAPI := &MockHistoricalAPI{}
+
+	handler := NewHandler(mockAPI, currency.NewErrorHandler(), 31, 3)
+
+	days, err := handler.parseRange("2025-05-01", "2025-05-31")
+	if err != nil {
+		t.Fatalf("parseRange() unexpected error: %v", err)
+	}
+
+	if len(days) != 31 {
+		t.Fatalf("parseRange() got %d days, want 31", len(days))
+	}
+
+	recorder := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(recorder)
+
+	c.Request = httptest.NewRequestWithContext(context.Background(), "GET",
+		"/rates/timeseries?from=EUR&to=PLN&start=2025-05-01&end=2025-05-31", nil)
+
+	handler.Handle(c)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %d want %d", recorder.Code, http.StatusOK)
+	}
+
+	if got := mockAPI.peak.Load(); got > 3 {
+		t.Errorf("%d concurrent upstream calls, want at most 3", got)
+	}
+
+	if got := mockAPI.calls.Load(); got != 31 {
+		t.Errorf("api called %d times, want 31", got)
+	}
+}
+
+func TestNewHandler_DefaultMaxDays(t *testing.T) {
+	handler := NewHandler(&MockHistoricalAPI{}, currency.NewErrorHandler(), 0, 0)
+
+	if _, err := handler.parseRange("2025-05-01", "2025-05-31"); err != nil {
+		t.Errorf("parseRange() unexpected error: %v", err)
+	}
+
+	if _, err := handler.parseRange("2025-05-01", "2025-06-01"); err == nil {
+		t.Errorf("parseRange() expected an error for 32 days")
+	}
+}
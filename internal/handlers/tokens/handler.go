@@ -0,0 +1,137 @@
+package tokens
+
+import (
+	"fmt"
+	"main/internal/errs"
+	"main/internal/repository/memory"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type Store interface {
+	ListTokens() []memory.VersionedToken
+	GetToken(symbol string) (memory.VersionedToken, error)
+	CreateToken(token memory.Token) (memory.VersionedToken, error)
+	UpdateToken(token memory.Token, version int64) (memory.VersionedToken, error)
+	DeleteToken(symbol string, version int64) error
+}
+
+type Request struct {
+	Symbol           string          `json:"symbol"`
+	DecimalPrecision int             `json:"decimalPrecision"`
+	Rate             decimal.Decimal `json:"rate"`
+	// Version is the version the update is based on, required by HandleUpdate.
+	Version *int64 `json:"version"`
+}
+
+type Handler struct {
+	store        Store
+	errorHandler errs.ErrorHandler
+}
+
+func NewHandler(
+	store Store,
+	errorHandler errs.ErrorHandler,
+) *Handler {
+	return &Handler{
+		store:        store,
+		errorHandler: errorHandler,
+	}
+}
+
+func (h *Handler) HandleList(c *gin.Context) {
+	c.JSON(http.StatusOK, h.store.ListTokens())
+}
+
+func (h *Handler) HandleGet(c *gin.Context) {
+	token, err := h.store.GetToken(c.Param("symbol"))
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *Handler) HandleCreate(c *gin.Context) {
+	var req Request
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorHandler.Handle(c, fmt.Errorf("%w: %w", errs.ErrInvalidToken, err))
+
+		return
+	}
+
+	token, err := h.store.CreateToken(memory.Token{
+		Symbol:           req.Symbol,
+		DecimalPrecision: req.DecimalPrecision,
+		Rate:             req.Rate,
+	})
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// HandleUpdate replaces the precision and rate of the token in the path.
+func (h *Handler) HandleUpdate(c *gin.Context) {
+	var req Request
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorHandler.Handle(c, fmt.Errorf("%w: %w", errs.ErrInvalidToken, err))
+
+		return
+	}
+
+	symbol := c.Param("symbol")
+
+	if req.Symbol != "" && req.Symbol != symbol {
+		h.errorHandler.Handle(c, fmt.Errorf("%w: symbol %s does not match the path", errs.ErrInvalidToken, req.Symbol))
+
+		return
+	}
+
+	if req.Version == nil {
+		h.errorHandler.Handle(c, fmt.Errorf("%w: version is required", errs.ErrInvalidToken))
+
+		return
+	}
+
+	token, err := h.store.UpdateToken(memory.Token{
+		Symbol:           symbol,
+		DecimalPrecision: req.DecimalPrecision,
+		Rate:             req.Rate,
+	}, *req.Version)
+	if err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// HandleDelete removes the token in the path, the version query parameter is required.
+func (h *Handler) HandleDelete(c *gin.Context) {
+	version, err := strconv.ParseInt(c.Query("version"), 10, 64)
+	if err != nil {
+		h.errorHandler.Handle(c, fmt.Errorf("%w: version is required", errs.ErrInvalidToken))
+
+		return
+	}
+
+	if err := h.store.DeleteToken(c.Param("symbol"), version); err != nil {
+		h.errorHandler.Handle(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
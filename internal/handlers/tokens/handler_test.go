@@ -0,0 +1,213 @@
+package tokens
+
+import (
+	"encoding/json"
+	"main/internal/errs/currency"
+	"main/internal/handlers/auth"
+	"main/internal/repository/memory"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminToken = "secret"
+
+func newRouter() (*gin.Engine, *memory.CurrencyRateRepo) {
+	repo := memory.NewCurrencyRateRepo()
+	handler := NewHandler(repo, currency.NewErrorHandler())
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+
+	admin := router.Group("/admin")
+	admin.Use(auth.NewHandler(adminToken).Handle)
+	admin.GET("/tokens", handler.HandleList)
+	admin.GET("/tokens/:symbol", handler.HandleGet)
+	admin.POST("/tokens", handler.HandleCreate)
+	admin.PUT("/tokens/:symbol", handler.HandleUpdate)
+	admin.DELETE("/tokens/:symbol", handler.HandleDelete)
+
+	return router, repo
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		token      string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "missing token, status 401",
+			method:     http.MethodGet,
+			url:        "/admin/tokens",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong token, status 401",
+			method:     http.MethodGet,
+			url:        "/admin/tokens",
+			token:      "guess",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "get token, status ok",
+			method:     http.MethodGet,
+			url:        "/admin/tokens/GATE",
+			token:      adminToken,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"symbol":"GATE","decimalPrecision":18,"rate":"6.87","version":1}`,
+		},
+		{
+			name:       "unknown token, status 404",
+			method:     http.MethodGet,
+			url:        "/admin/tokens/DOGE",
+			token:      adminToken,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "create token, status 201",
+			method:     http.MethodPost,
+			url:        "/admin/tokens",
+			token:      adminToken,
+			body:       `{"symbol":"DOGE","decimalPrecision":8,"rate":"0.12"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"symbol":"DOGE","decimalPrecision":8,"rate":"0.12","version":1}`,
+		},
+		{
+			name:       "create existing token, status 409",
+			method:     http.MethodPost,
+			url:        "/admin/tokens",
+			token:      adminToken,
+			body:       `{"symbol":"GATE","decimalPrecision":8,"rate":"0.12"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "create token without precision, status 400",
+			method:     http.MethodPost,
+			url:        "/admin/tokens",
+			token:      adminToken,
+			body:       `{"symbol":"DOGE","rate":"0.12"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update token, status ok",
+			method:     http.MethodPut,
+			url:        "/admin/tokens/GATE",
+			token:      adminToken,
+			body:       `{"decimalPrecision":18,"rate":"7.01","version":1}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"symbol":"GATE","decimalPrecision":18,"rate":"7.01","version":2}`,
+		},
+		{
+			name:       "update stale version, status 409",
+			method:     http.MethodPut,
+			url:        "/admin/tokens/GATE",
+			token:      adminToken,
+			body:       `{"decimalPrecision":18,"rate":"7.01","version":0}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "update without version, status 400",
+			method:     http.MethodPut,
+			url:        "/admin/tokens/GATE",
+			token:      adminToken,
+			body:       `{"decimalPrecision":18,"rate":"7.01"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update non-positive rate, status 400",
+			method:     http.MethodPut,
+			url:        "/admin/tokens/GATE",
+			token:      adminToken,
+			body:       `{"decimalPrecision":18,"rate":"0","version":1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "delete token, status 204",
+			method:     http.MethodDelete,
+			url:        "/admin/tokens/GATE?version=1",
+			token:      adminToken,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete stale version, status 409",
+			method:     http.MethodDelete,
+			url:        "/admin/tokens/GATE?version=2",
+			token:      adminToken,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "delete without version, status 400",
+			method:     http.MethodDelete,
+			url:        "/admin/tokens/GATE",
+			token:      adminToken,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newRouter()
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %d want %d, body %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			if tt.wantBody != "" {
+				var got, want memory.VersionedToken
+
+				_ = json.Unmarshal(recorder.Body.Bytes(), &got)
+				_ = json.Unmarshal([]byte(tt.wantBody), &want)
+
+				if got.UpdatedAt.IsZero() {
+					t.Errorf("handler got = %s, want updatedAt", recorder.Body)
+				}
+
+				got.UpdatedAt = time.Time{}
+
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("handler got = %s, want %s", recorder.Body, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateIsServedByExchange(t *testing.T) {
+	router, repo := newRouter()
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/tokens/WBTC",
+		strings.NewReader(`{"decimalPrecision":8,"rate":"60000","version":1}`))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	var token memory.VersionedToken
+	if err := json.Unmarshal(recorder.Body.Bytes(), &token); err != nil || token.Version != 2 {
+		t.Fatalf("HandleUpdate() body = %s, error = %v", recorder.Body, err)
+	}
+
+	details, err := repo.Get("WBTC")
+	if err != nil || details.Rate.String() != "60000" {
+		t.Errorf("Get() got = %v, error = %v, want the updated rate", details, err)
+	}
+}
@@ -0,0 +1,32 @@
+package usage
+
+import (
+	"main/internal/api/quota"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Budget interface {
+	State() quota.State
+}
+
+type Handler struct {
+	budgets map[string]Budget
+}
+
+func NewHandler(budgets map[string]Budget) *Handler {
+	return &Handler{
+		budgets: budgets,
+	}
+}
+
+func (h *Handler) Handle(c *gin.Context) {
+	states := make(map[string]quota.State, len(h.budgets))
+
+	for name, budget := range h.budgets {
+		states[name] = budget.State()
+	}
+
+	c.JSON(http.StatusOK, states)
+}
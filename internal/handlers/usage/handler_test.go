@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"encoding/json"
+	"main/internal/api"
+	"main/internal/api/quota"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MockBudget struct {
+	state quota.State
+}
+
+func (m MockBudget) State() quota.State {
+	return m.state
+}
+
+func TestHandler_Handle(t *testing.T) {
+	usage := &api.Usage{Plan: "Free", Requests: 900, RequestsQuota: 1000, DaysElapsed: 15, DaysRemaining: 15}
+
+	tests := []struct {
+		name     string
+		budgets  map[string]Budget
+		wantBody map[string]quota.State
+	}{
+		{
+			name:     "no tracked provider, empty object",
+			wantBody: map[string]quota.State{},
+		},
+		{
+			name: "state of every provider",
+			budgets: map[string]Budget{
+				"openexchange": MockBudget{state: quota.State{Usage: usage, LocalRequests: 3, Projected: 1806, Throttled: true}},
+				"backup":       MockBudget{state: quota.State{LastError: "error api response"}},
+			},
+			wantBody: map[string]quota.State{
+				"openexchange": {Usage: usage, LocalRequests: 3, Projected: 1806, Throttled: true},
+				"backup":       {LastError: "error api response"},
+			},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/admin/usage", NewHandler(tt.budgets).Handle)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/usage", nil))
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Handle() status = %v, want %v", recorder.Code, http.StatusOK)
+			}
+
+			var got map[string]quota.State
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("Handle() got = %+v, want %+v", got, tt.wantBody)
+			}
+		})
+	}
+}
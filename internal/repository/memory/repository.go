@@ -1,52 +1,203 @@
 package memory
 
 import (
+	"cmp"
+	"fmt"
 	"main/internal/errs"
-)
+	"slices"
+	"sync"
+	"time"
 
-const (
-	_ = iota
-	beer
-	floki
-	gate
-	usdt
-	wbtc
+	"github.com/shopspring/decimal"
 )
 
 type CurrencyDetails struct {
 	DecimalPrecision int
-	Rate             float64
+	Rate             decimal.Decimal
+}
+
+// VersionedToken is a token with the version it was last written at, starting at 1.
+// Updates and deletes have to name the version they were based on.
+type VersionedToken struct {
+	Token
+	Version   int64     `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type entry struct {
+	details   CurrencyDetails
+	version   int64
+	updatedAt time.Time
 }
 
+// CurrencyRateRepo holds the tokens supported by /exchange, keyed by symbol.
 type CurrencyRateRepo struct {
-	Storage []CurrencyDetails
+	mu     sync.RWMutex
+	tokens map[string]entry
 }
 
+// NewCurrencyRateRepo returns a repository of the default dataset.
 func NewCurrencyRateRepo() *CurrencyRateRepo {
-	return &CurrencyRateRepo{
-		Storage: []CurrencyDetails{
-			beer:  {18, 0.00002461},
-			floki: {18, 0.0001428},
-			gate:  {18, 6.87},
-			usdt:  {6, 0.999},
-			wbtc:  {8, 57037.22},
-		},
+	repo, err := NewCurrencyRateRepoFromTokens(DefaultTokens())
+	if err != nil {
+		panic(err)
+	}
+
+	return repo
+}
+
+// NewCurrencyRateRepoFromTokens validates the tokens before they can be served.
+func NewCurrencyRateRepoFromTokens(tokens []Token) (*CurrencyRateRepo, error) {
+	if err := ValidateTokens(tokens); err != nil {
+		return nil, err
+	}
+
+	repo := &CurrencyRateRepo{
+		tokens: make(map[string]entry, len(tokens)),
 	}
+
+	loadedAt := time.Now().UTC()
+
+	for _, token := range tokens {
+		repo.tokens[token.Symbol] = entry{details: token.details(), version: 1, updatedAt: loadedAt}
+	}
+
+	return repo, nil
 }
 
 func (repo *CurrencyRateRepo) Get(currency string) (CurrencyDetails, error) {
-	switch currency {
-	case "BEER":
-		return repo.Storage[beer], nil
-	case "FLOKI":
-		return repo.Storage[floki], nil
-	case "GATE":
-		return repo.Storage[gate], nil
-	case "USDT":
-		return repo.Storage[usdt], nil
-	case "WBTC":
-		return repo.Storage[wbtc], nil
-	default:
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	e, ok := repo.tokens[currency]
+	if !ok {
 		return CurrencyDetails{}, errs.ErrRepoCurrencyNotFound
 	}
+
+	return e.details, nil
+}
+
+func (repo *CurrencyRateRepo) List() map[string]CurrencyDetails {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	list := make(map[string]CurrencyDetails, len(repo.tokens))
+	for symbol, e := range repo.tokens {
+		list[symbol] = e.details
+	}
+
+	return list
+}
+
+// ListTokens returns the tokens sorted by symbol.
+func (repo *CurrencyRateRepo) ListTokens() []VersionedToken {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	list := make([]VersionedToken, 0, len(repo.tokens))
+	for symbol, e := range repo.tokens {
+		list = append(list, e.token(symbol))
+	}
+
+	slices.SortFunc(list, func(a, b VersionedToken) int {
+		return cmp.Compare(a.Symbol, b.Symbol)
+	})
+
+	return list
+}
+
+func (repo *CurrencyRateRepo) GetToken(symbol string) (VersionedToken, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	e, ok := repo.tokens[symbol]
+	if !ok {
+		return VersionedToken{}, fmt.Errorf("%w: %s", errs.ErrTokenNotFound, symbol)
+	}
+
+	return e.token(symbol), nil
+}
+
+func (repo *CurrencyRateRepo) CreateToken(token Token) (VersionedToken, error) {
+	if err := ValidateToken(token); err != nil {
+		return VersionedToken{}, fmt.Errorf("%w: %w", errs.ErrInvalidToken, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.tokens[token.Symbol]; ok {
+		return VersionedToken{}, fmt.Errorf("%w: %s", errs.ErrTokenExists, token.Symbol)
+	}
+
+	e := entry{details: token.details(), version: 1, updatedAt: time.Now().UTC()}
+	repo.tokens[token.Symbol] = e
+
+	return e.token(token.Symbol), nil
+}
+
+// UpdateToken replaces the token if it is still at version.
+func (repo *CurrencyRateRepo) UpdateToken(token Token, version int64) (VersionedToken, error) {
+	if err := ValidateToken(token); err != nil {
+		return VersionedToken{}, fmt.Errorf("%w: %w", errs.ErrInvalidToken, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	e, err := repo.current(token.Symbol, version)
+	if err != nil {
+		return VersionedToken{}, err
+	}
+
+	e = entry{details: token.details(), version: e.version + 1, updatedAt: time.Now().UTC()}
+	repo.tokens[token.Symbol] = e
+
+	return e.token(token.Symbol), nil
+}
+
+// DeleteToken removes the token if it is still at version.
+func (repo *CurrencyRateRepo) DeleteToken(symbol string, version int64) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, err := repo.current(symbol, version); err != nil {
+		return err
+	}
+
+	delete(repo.tokens, symbol)
+
+	return nil
+}
+
+func (repo *CurrencyRateRepo) current(symbol string, version int64) (entry, error) {
+	e, ok := repo.tokens[symbol]
+	if !ok {
+		return entry{}, fmt.Errorf("%w: %s", errs.ErrTokenNotFound, symbol)
+	}
+
+	if e.version != version {
+		return entry{}, fmt.Errorf("%w: %s is at version %d", errs.ErrVersionConflict, symbol, e.version)
+	}
+
+	return e, nil
+}
+
+func (e entry) token(symbol string) VersionedToken {
+	return VersionedToken{
+		Token: Token{
+			Symbol:           symbol,
+			DecimalPrecision: e.details.DecimalPrecision,
+			Rate:             e.details.Rate,
+		},
+		Version:   e.version,
+		UpdatedAt: e.updatedAt,
+	}
+}
+
+func (t Token) details() CurrencyDetails {
+	return CurrencyDetails{
+		DecimalPrecision: t.DecimalPrecision,
+		Rate:             t.Rate,
+	}
 }
@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"errors"
+	"main/internal/errs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNewCurrencyRateRepo_DefaultDataset(t *testing.T) {
+	repo := NewCurrencyRateRepo()
+
+	want := map[string]CurrencyDetails{
+		"BEER":  {18, decimal.RequireFromString("0.00002461")},
+		"FLOKI": {18, decimal.RequireFromString("0.0001428")},
+		"GATE":  {18, decimal.RequireFromString("6.87")},
+		"USDT":  {6, decimal.RequireFromString("0.999")},
+		"WBTC":  {8, decimal.RequireFromString("57037.22")},
+	}
+
+	got := repo.List()
+	if len(got) != len(want) {
+		t.Fatalf("List() got %d tokens, want %d", len(got), len(want))
+	}
+
+	for symbol, details := range want {
+		gotDetails, err := repo.Get(symbol)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", symbol, err)
+		}
+
+		if gotDetails.DecimalPrecision != details.DecimalPrecision || !gotDetails.Rate.Equal(details.Rate) {
+			t.Errorf("Get(%s) got = %v, want %v", symbol, gotDetails, details)
+		}
+	}
+
+	if _, err := repo.Get("DOGE"); !errors.Is(err, errs.ErrRepoCurrencyNotFound) {
+		t.Errorf("Get(DOGE) error = %v, want %v", err, errs.ErrRepoCurrencyNotFound)
+	}
+}
+
+func TestLoadTokens_YAML(t *testing.T) {
+	tokens, err := LoadTokens(filepath.Join("testdata", "tokens.yaml"))
+	if err != nil {
+		t.Fatalf("LoadTokens() error = %v", err)
+	}
+
+	repo, err := NewCurrencyRateRepoFromTokens(tokens)
+	if err != nil {
+		t.Fatalf("NewCurrencyRateRepoFromTokens() error = %v", err)
+	}
+
+	pepe, err := repo.Get("PEPE")
+	if err != nil {
+		t.Fatalf("Get(PEPE) error = %v", err)
+	}
+
+	if pepe.Rate.String() != "0.000000012345678901" || pepe.DecimalPrecision != 18 {
+		t.Errorf("Get(PEPE) got = %v, want the exact rate", pepe)
+	}
+}
+
+func TestParseTokens_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "unknown format",
+			file:    "tokens.toml",
+			data:    `symbol = "BEER"`,
+			wantErr: "unsupported tokens file",
+		},
+		{
+			name:    "malformed json",
+			file:    "tokens.json",
+			data:    `[{"symbol":`,
+			wantErr: "error parsing tokens",
+		},
+		{
+			name:    "rate not a number",
+			file:    "tokens.yaml",
+			data:    "- symbol: BEER\n  decimalPrecision: 18\n  rate: cheap\n",
+			wantErr: "error parsing tokens",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTokens([]byte(tt.data), tt.file)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseTokens() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   string
+		wantErrs []string
+	}{
+		{
+			name:   "valid",
+			tokens: `[{"symbol":"BEER","decimalPrecision":18,"rate":"0.00002461"},{"symbol":"USDT","decimalPrecision":6,"rate":0.999}]`,
+		},
+		{
+			name:     "duplicate symbol",
+			tokens:   `[{"symbol":"BEER","decimalPrecision":18,"rate":"1"},{"symbol":"BEER","decimalPrecision":8,"rate":"2"}]`,
+			wantErrs: []string{"token 1: duplicate symbol BEER"},
+		},
+		{
+			name:     "zero precision",
+			tokens:   `[{"symbol":"BEER","rate":"1"}]`,
+			wantErrs: []string{"BEER: decimal precision must be positive"},
+		},
+		{
+			name:     "non-positive rates",
+			tokens:   `[{"symbol":"BEER","decimalPrecision":18,"rate":"0"},{"symbol":"GATE","decimalPrecision":18,"rate":"-6.87"}]`,
+			wantErrs: []string{"BEER: rate must be positive", "GATE: rate must be positive"},
+		},
+		{
+			name:     "empty symbol",
+			tokens:   `[{"decimalPrecision":18,"rate":"1"}]`,
+			wantErrs: []string{"token 0: empty symbol"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := ParseTokens([]byte(tt.tokens), "tokens.json")
+			if err != nil {
+				t.Fatalf("ParseTokens() error = %v", err)
+			}
+
+			_, err = NewCurrencyRateRepoFromTokens(tokens)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("NewCurrencyRateRepoFromTokens() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("NewCurrencyRateRepoFromTokens() error = nil, want %v", tt.wantErrs)
+			}
+
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("NewCurrencyRateRepoFromTokens() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCurrencyRateRepo_ConcurrentUpdates(t *testing.T) {
+	repo := NewCurrencyRateRepo()
+
+	const writers = 20
+
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+
+	for i := range writers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			token := Token{Symbol: "GATE", DecimalPrecision: 18, Rate: decimal.NewFromInt(int64(i + 1))}
+
+			_, err := repo.UpdateToken(token, 1)
+			switch {
+			case err == nil:
+				succeeded.Add(1)
+			case !errors.Is(err, errs.ErrVersionConflict):
+				t.Errorf("UpdateToken() error = %v, want %v", err, errs.ErrVersionConflict)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := succeeded.Load(); got != 1 {
+		t.Errorf("UpdateToken() succeeded %d times for the same version, want 1", got)
+	}
+
+	token, err := repo.GetToken("GATE")
+	if err != nil || token.Version != 2 {
+		t.Errorf("GetToken() got = %+v, error = %v, want version 2", token, err)
+	}
+
+	if err := repo.DeleteToken("GATE", 1); !errors.Is(err, errs.ErrVersionConflict) {
+		t.Errorf("DeleteToken() error = %v, want %v", err, errs.ErrVersionConflict)
+	}
+
+	if err := repo.DeleteToken("GATE", 2); err != nil {
+		t.Errorf("DeleteToken() error = %v", err)
+	}
+
+	if _, err := repo.Get("GATE"); !errors.Is(err, errs.ErrRepoCurrencyNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, errs.ErrRepoCurrencyNotFound)
+	}
+}
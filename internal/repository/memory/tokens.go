@@ -0,0 +1,102 @@
+package memory
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultTokensFile = "tokens.json"
+
+//go:embed tokens.json
+var defaultTokens []byte
+
+// Token is an entry of a token dataset file.
+type Token struct {
+	Symbol           string          `json:"symbol" yaml:"symbol"`
+	DecimalPrecision int             `json:"decimalPrecision" yaml:"decimalPrecision"`
+	Rate             decimal.Decimal `json:"rate" yaml:"rate"`
+}
+
+// DefaultTokens returns the dataset used when no tokens file is configured.
+func DefaultTokens() []Token {
+	tokens, err := ParseTokens(defaultTokens, defaultTokensFile)
+	if err != nil {
+		panic(err)
+	}
+
+	return tokens
+}
+
+// LoadTokens reads a JSON or YAML token dataset, told apart by the file extension.
+func LoadTokens(path string) ([]Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tokens %s: %w", path, err)
+	}
+
+	return ParseTokens(data, path)
+}
+
+func ParseTokens(data []byte, name string) ([]Token, error) {
+	var tokens []Token
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		if err := json.Unmarshal(data, &tokens); err != nil {
+			return nil, fmt.Errorf("error parsing tokens %s: %w", name, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &tokens); err != nil {
+			return nil, fmt.Errorf("error parsing tokens %s: %w", name, err)
+		}
+	default:
+		return nil, fmt.Errorf("error unsupported tokens file %s, want .json, .yaml or .yml", name)
+	}
+
+	return tokens, nil
+}
+
+// ValidateTokens reports every invalid entry, not only the first one.
+func ValidateTokens(tokens []Token) error {
+	var problems []error
+
+	seen := make(map[string]bool, len(tokens))
+
+	for i, token := range tokens {
+		if err := ValidateToken(token); err != nil {
+			problems = append(problems, fmt.Errorf("token %d: %w", i, err))
+		}
+
+		if token.Symbol != "" && seen[token.Symbol] {
+			problems = append(problems, fmt.Errorf("token %d: duplicate symbol %s", i, token.Symbol))
+		}
+
+		seen[token.Symbol] = true
+	}
+
+	return errors.Join(problems...)
+}
+
+func ValidateToken(token Token) error {
+	if token.Symbol == "" {
+		return errors.New("empty symbol")
+	}
+
+	if token.DecimalPrecision <= 0 {
+		return fmt.Errorf("%s: decimal precision must be positive, got %d", token.Symbol, token.DecimalPrecision)
+	}
+
+	if !token.Rate.IsPositive() {
+		return fmt.Errorf("%s: rate must be positive, got %s", token.Symbol, token.Rate)
+	}
+
+	return nil
+}
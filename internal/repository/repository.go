@@ -5,3 +5,7 @@ import "main/internal/repository/memory"
 type CurrencyRate interface {
 	Get(currency string) (memory.CurrencyDetails, error)
 }
+
+type CurrencyList interface {
+	List() map[string]memory.CurrencyDetails
+}
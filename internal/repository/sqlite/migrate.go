@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+type migration struct {
+	version int
+	name    string
+	query   string
+}
+
+// migrate applies the migrations newer than the schema, each in its own transaction.
+// Migrations are never edited once released, a schema change is a new numbered file.
+func migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT    NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	var current int
+
+	err = db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("error reading schema version: %w", err)
+	}
+
+	pending, err := loadMigrations(current)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		if err := apply(ctx, db, m); err != nil {
+			return err
+		}
+
+		slog.Info("applied database migration", slog.String("migration", m.name))
+	}
+
+	return nil
+}
+
+func loadMigrations(after int) ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %w", err)
+	}
+
+	var pending []migration
+
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("error migration %s is not numbered: %w", entry.Name(), err)
+		}
+
+		if version <= after {
+			continue
+		}
+
+		query, err := migrations.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		pending = append(pending, migration{version: version, name: entry.Name(), query: string(query)})
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].version < pending[j].version
+	})
+
+	return pending, nil
+}
+
+func apply(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting migration %s: %w", m.name, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	if _, err := tx.ExecContext(ctx, m.query); err != nil {
+		return fmt.Errorf("error applying migration %s: %w", m.name, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+		m.version, time.Now().UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("error recording migration %s: %w", m.name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing migration %s: %w", m.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+CREATE TABLE tokens (
+    symbol            TEXT    PRIMARY KEY,
+    decimal_precision INTEGER NOT NULL CHECK (decimal_precision > 0),
+    -- rates are kept as decimal strings, REAL would lose digits
+    rate              TEXT    NOT NULL,
+    version           INTEGER NOT NULL DEFAULT 1,
+    created_at        TEXT    NOT NULL,
+    updated_at        TEXT    NOT NULL
+);
@@ -0,0 +1,7 @@
+-- the seed is applied once, a repository emptied through /admin/tokens stays empty
+CREATE TABLE seeds (
+    seeded_at TEXT NOT NULL
+);
+
+-- databases created before this table were seeded already
+INSERT INTO seeds (seeded_at) SELECT MIN(created_at) FROM tokens HAVING COUNT(*) > 0;
@@ -0,0 +1,283 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"main/internal/errs"
+	"main/internal/repository/memory"
+	"time"
+
+	"github.com/shopspring/decimal"
+	_ "modernc.org/sqlite" // registers the pure Go "sqlite" driver
+)
+
+const tokenColumns = `symbol, decimal_precision, rate, version, updated_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// CurrencyRateRepo keeps the tokens supported by /exchange in a SQLite database,
+// so the changes made with /admin/tokens survive restarts.
+type CurrencyRateRepo struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// Open migrates the database at path and fills it with seed the first time it is opened.
+func Open(path string, seed []memory.Token) (*CurrencyRateRepo, error) {
+	if err := memory.ValidateTokens(seed); err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database %s: %w", path, err)
+	}
+
+	// A single connection serializes the writes, SQLite allows one writer at a time anyway.
+	db.SetMaxOpenConns(1)
+
+	repo := &CurrencyRateRepo{
+		db:  db,
+		now: time.Now,
+	}
+
+	ctx := context.Background()
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	if err := repo.seed(ctx, seed); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func (repo *CurrencyRateRepo) Close() error {
+	return repo.db.Close()
+}
+
+func (repo *CurrencyRateRepo) seed(ctx context.Context, tokens []memory.Token) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting seed: %w", err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	var seeded int
+
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM seeds`).Scan(&seeded); err != nil {
+		return fmt.Errorf("error checking seed: %w", err)
+	}
+
+	if seeded > 0 {
+		return nil
+	}
+
+	now := repo.timestamp()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO seeds (seeded_at) VALUES (?)`, now); err != nil {
+		return fmt.Errorf("error recording seed: %w", err)
+	}
+
+	for _, token := range tokens {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO tokens (symbol, decimal_precision, rate, version, created_at, updated_at) VALUES (?, ?, ?, 1, ?, ?)`,
+			token.Symbol, token.DecimalPrecision, token.Rate.String(), now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("error seeding token %s: %w", token.Symbol, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing seed: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *CurrencyRateRepo) Get(currency string) (memory.CurrencyDetails, error) {
+	token, err := repo.GetToken(currency)
+	if errors.Is(err, errs.ErrTokenNotFound) {
+		return memory.CurrencyDetails{}, errs.ErrRepoCurrencyNotFound
+	}
+
+	if err != nil {
+		return memory.CurrencyDetails{}, err
+	}
+
+	return memory.CurrencyDetails{
+		DecimalPrecision: token.DecimalPrecision,
+		Rate:             token.Rate,
+	}, nil
+}
+
+// List returns no tokens when the database can not be read, the error is logged.
+func (repo *CurrencyRateRepo) List() map[string]memory.CurrencyDetails {
+	list := make(map[string]memory.CurrencyDetails)
+
+	for _, token := range repo.ListTokens() {
+		list[token.Symbol] = memory.CurrencyDetails{
+			DecimalPrecision: token.DecimalPrecision,
+			Rate:             token.Rate,
+		}
+	}
+
+	return list
+}
+
+// ListTokens returns the tokens sorted by symbol, none when the database can not be read.
+func (repo *CurrencyRateRepo) ListTokens() []memory.VersionedToken {
+	rows, err := repo.db.Query(`SELECT ` + tokenColumns + ` FROM tokens ORDER BY symbol`)
+	if err != nil {
+		slog.Error("failed to list tokens", slog.String("err", err.Error()))
+
+		return []memory.VersionedToken{}
+	}
+
+	defer rows.Close()
+
+	list := []memory.VersionedToken{}
+
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			slog.Error("failed to list tokens", slog.String("err", err.Error()))
+
+			return []memory.VersionedToken{}
+		}
+
+		list = append(list, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("failed to list tokens", slog.String("err", err.Error()))
+
+		return []memory.VersionedToken{}
+	}
+
+	return list
+}
+
+func (repo *CurrencyRateRepo) GetToken(symbol string) (memory.VersionedToken, error) {
+	row := repo.db.QueryRow(`SELECT `+tokenColumns+` FROM tokens WHERE symbol = ?`, symbol)
+
+	token, err := scanToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return memory.VersionedToken{}, fmt.Errorf("%w: %s", errs.ErrTokenNotFound, symbol)
+	}
+
+	return token, err
+}
+
+func (repo *CurrencyRateRepo) CreateToken(token memory.Token) (memory.VersionedToken, error) {
+	if err := memory.ValidateToken(token); err != nil {
+		return memory.VersionedToken{}, fmt.Errorf("%w: %w", errs.ErrInvalidToken, err)
+	}
+
+	now := repo.timestamp()
+
+	result, err := repo.db.Exec(
+		`INSERT INTO tokens (symbol, decimal_precision, rate, version, created_at, updated_at)
+		VALUES (?, ?, ?, 1, ?, ?) ON CONFLICT (symbol) DO NOTHING`,
+		token.Symbol, token.DecimalPrecision, token.Rate.String(), now, now,
+	)
+	if err != nil {
+		return memory.VersionedToken{}, fmt.Errorf("error creating token %s: %w", token.Symbol, err)
+	}
+
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		return memory.VersionedToken{}, fmt.Errorf("%w: %s", errs.ErrTokenExists, token.Symbol)
+	}
+
+	return repo.GetToken(token.Symbol)
+}
+
+// UpdateToken replaces the token if it is still at version.
+func (repo *CurrencyRateRepo) UpdateToken(token memory.Token, version int64) (memory.VersionedToken, error) {
+	if err := memory.ValidateToken(token); err != nil {
+		return memory.VersionedToken{}, fmt.Errorf("%w: %w", errs.ErrInvalidToken, err)
+	}
+
+	result, err := repo.db.Exec(
+		`UPDATE tokens SET decimal_precision = ?, rate = ?, version = version + 1, updated_at = ?
+		WHERE symbol = ? AND version = ?`,
+		token.DecimalPrecision, token.Rate.String(), repo.timestamp(), token.Symbol, version,
+	)
+	if err != nil {
+		return memory.VersionedToken{}, fmt.Errorf("error updating token %s: %w", token.Symbol, err)
+	}
+
+	if err := repo.checkWritten(result, token.Symbol); err != nil {
+		return memory.VersionedToken{}, err
+	}
+
+	return repo.GetToken(token.Symbol)
+}
+
+// DeleteToken removes the token if it is still at version.
+func (repo *CurrencyRateRepo) DeleteToken(symbol string, version int64) error {
+	result, err := repo.db.Exec(`DELETE FROM tokens WHERE symbol = ? AND version = ?`, symbol, version)
+	if err != nil {
+		return fmt.Errorf("error deleting token %s: %w", symbol, err)
+	}
+
+	return repo.checkWritten(result, symbol)
+}
+
+// checkWritten tells why a versioned write matched no row.
+func (repo *CurrencyRateRepo) checkWritten(result sql.Result, symbol string) error {
+	if written, _ := result.RowsAffected(); written > 0 {
+		return nil
+	}
+
+	current, err := repo.GetToken(symbol)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s is at version %d", errs.ErrVersionConflict, symbol, current.Version)
+}
+
+func (repo *CurrencyRateRepo) timestamp() string {
+	return repo.now().UTC().Format(time.RFC3339Nano)
+}
+
+func scanToken(row scanner) (memory.VersionedToken, error) {
+	var (
+		token     memory.VersionedToken
+		rate      string
+		updatedAt string
+	)
+
+	err := row.Scan(&token.Symbol, &token.DecimalPrecision, &rate, &token.Version, &updatedAt)
+	if err != nil {
+		return memory.VersionedToken{}, err
+	}
+
+	token.Rate, err = decimal.NewFromString(rate)
+	if err != nil {
+		return memory.VersionedToken{}, fmt.Errorf("error parsing %s rate %s: %w", token.Symbol, rate, err)
+	}
+
+	token.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return memory.VersionedToken{}, fmt.Errorf("error parsing %s update time %s: %w", token.Symbol, updatedAt, err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,218 @@
+package sqlite
+
+import (
+	"errors"
+	"main/internal/errs"
+	"main/internal/handlers/exchange"
+	"main/internal/repository"
+	"main/internal/repository/memory"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var _ repository.CurrencyRate = (*CurrencyRateRepo)(nil)
+
+func openTestRepo(t *testing.T, path string) *CurrencyRateRepo {
+	t.Helper()
+
+	repo, err := Open(path, memory.DefaultTokens())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		repo.Close()
+	})
+
+	return repo
+}
+
+func TestOpen_SeedsDefaultDataset(t *testing.T) {
+	repo := openTestRepo(t, filepath.Join(t.TempDir(), "tokens.db"))
+
+	if got := repo.List(); len(got) != 5 {
+		t.Errorf("List() got %d tokens, want 5", len(got))
+	}
+
+	// The same repository serves /exchange.
+	quote, err := exchange.Quote(repo, "WBTC", "USDT", decimal.NewFromInt(1))
+	if err != nil {
+		t.Fatalf("Quote() error = %v", err)
+	}
+
+	if quote.Amount != "57094.314314" {
+		t.Errorf("Quote() got = %v, want %v", quote.Amount, "57094.314314")
+	}
+
+	if _, err := repo.Get("DOGE"); !errors.Is(err, errs.ErrRepoCurrencyNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, errs.ErrRepoCurrencyNotFound)
+	}
+}
+
+func TestOpen_RejectsInvalidSeed(t *testing.T) {
+	seed := []memory.Token{{Symbol: "BEER", DecimalPrecision: 0, Rate: decimal.NewFromInt(1)}}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "tokens.db"), seed); err == nil {
+		t.Errorf("Open() error = nil, want the zero precision reported")
+	}
+}
+
+func TestCurrencyRateRepo_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.db")
+
+	repo, err := Open(path, memory.DefaultTokens())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	pepe := memory.Token{Symbol: "PEPE", DecimalPrecision: 18, Rate: decimal.RequireFromString("0.000000012345678901")}
+
+	if _, err := repo.CreateToken(pepe); err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	if err := repo.DeleteToken("GATE", 1); err != nil {
+		t.Fatalf("DeleteToken() error = %v", err)
+	}
+
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Reopening neither reapplies the migrations nor the seed.
+	reopened := openTestRepo(t, path)
+
+	got, err := reopened.GetToken("PEPE")
+	if err != nil {
+		t.Fatalf("GetToken() error = %v", err)
+	}
+
+	if got.Rate.String() != "0.000000012345678901" || got.Version != 1 {
+		t.Errorf("GetToken() got = %+v, want the exact rate at version 1", got)
+	}
+
+	if _, err := reopened.GetToken("GATE"); !errors.Is(err, errs.ErrTokenNotFound) {
+		t.Errorf("GetToken() error = %v, want %v", err, errs.ErrTokenNotFound)
+	}
+
+	var migrations int
+
+	if err := reopened.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&migrations); err != nil {
+		t.Fatalf("schema_migrations error = %v", err)
+	}
+
+	if migrations != 2 {
+		t.Errorf("schema_migrations got %d rows, want 2", migrations)
+	}
+}
+
+func TestCurrencyRateRepo_StaysEmptyAfterDeletingEverything(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.db")
+
+	repo, err := Open(path, memory.DefaultTokens())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	for _, token := range repo.ListTokens() {
+		if err := repo.DeleteToken(token.Symbol, token.Version); err != nil {
+			t.Fatalf("DeleteToken() error = %v", err)
+		}
+	}
+
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got := openTestRepo(t, path).ListTokens(); len(got) != 0 {
+		t.Errorf("ListTokens() got %d tokens after reopening, want none", len(got))
+	}
+}
+
+func TestCurrencyRateRepo_Versions(t *testing.T) {
+	repo := openTestRepo(t, filepath.Join(t.TempDir(), "tokens.db"))
+
+	updatedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	repo.now = func() time.Time { return updatedAt }
+
+	token := memory.Token{Symbol: "GATE", DecimalPrecision: 18, Rate: decimal.RequireFromString("7.01")}
+
+	got, err := repo.UpdateToken(token, 1)
+	if err != nil {
+		t.Fatalf("UpdateToken() error = %v", err)
+	}
+
+	if got.Version != 2 || !got.Rate.Equal(token.Rate) || !got.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("UpdateToken() got = %+v, want version 2 updated at %v", got, updatedAt)
+	}
+
+	tests := []struct {
+		name    string
+		write   func() error
+		wantErr error
+	}{
+		{
+			name: "update stale version",
+			write: func() error {
+				_, err := repo.UpdateToken(token, 1)
+
+				return err
+			},
+			wantErr: errs.ErrVersionConflict,
+		},
+		{
+			name: "update unknown token",
+			write: func() error {
+				_, err := repo.UpdateToken(memory.Token{Symbol: "DOGE", DecimalPrecision: 8, Rate: decimal.NewFromInt(1)}, 1)
+
+				return err
+			},
+			wantErr: errs.ErrTokenNotFound,
+		},
+		{
+			name: "update non-positive rate",
+			write: func() error {
+				_, err := repo.UpdateToken(memory.Token{Symbol: "GATE", DecimalPrecision: 18}, 2)
+
+				return err
+			},
+			wantErr: errs.ErrInvalidToken,
+		},
+		{
+			name: "create existing token",
+			write: func() error {
+				_, err := repo.CreateToken(token)
+
+				return err
+			},
+			wantErr: errs.ErrTokenExists,
+		},
+		{
+			name:    "delete stale version",
+			write:   func() error { return repo.DeleteToken("GATE", 1) },
+			wantErr: errs.ErrVersionConflict,
+		},
+		{
+			name:    "delete current version",
+			write:   func() error { return repo.DeleteToken("GATE", 2) },
+			wantErr: nil,
+		},
+		{
+			name:    "delete deleted token",
+			write:   func() error { return repo.DeleteToken("GATE", 2) },
+			wantErr: errs.ErrTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.write()
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}